@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+	"net/http"
+)
+
+// DiscoverHandler は指定したページ（ブログのトップページ等）からフィードの候補を検出する
+// GET /api/discover?url=https://example.com/
+func DiscoverHandler(w http.ResponseWriter, r *http.Request) {
+	// CORS ヘッダー設定
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// プリフライト OPTIONS リクエストの処理
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	pageURL := r.URL.Query().Get("url")
+	if pageURL == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.DiscoverResponse{
+			Feeds: []models.FeedCandidate{},
+			Error: &models.ErrorInfo{Message: "invalid request", Code: models.ErrorCodeInvalidRequest, Detail: "url パラメータが必要です"},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), services.RequestTimeout)
+	defer cancel()
+	json.NewEncoder(w).Encode(services.SharedService().Discover(ctx, pageURL))
+}
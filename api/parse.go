@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"feed-parallel-parse-api/pkg/models"
 	"feed-parallel-parse-api/pkg/services"
@@ -8,10 +9,12 @@ import (
 )
 
 // Handler is the Vercel serverless function entry point
+// GET /api/parse?diagnostics はフィード取得サービスの内部状態（ホストごとのサーキットブレーカー等）を返す
+// （/api/diagnostics はここに書き換える。状態はフィードを取得するこの関数のインスタンス内でのみ共有される）
 func Handler(w http.ResponseWriter, r *http.Request) {
 	// CORS ヘッダー設定
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 	// プリフライト OPTIONS リクエストの処理
@@ -20,6 +23,12 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == http.MethodGet && r.URL.Query().Has("diagnostics") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(services.SharedService().Diagnostics())
+		return
+	}
+
 	// Only allow POST method
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -30,14 +39,21 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	var req models.ParseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.ParseResponse{Feeds: nil, Errors: []models.ErrorInfo{{URL: "", Message: "invalid request"}}})
+		json.NewEncoder(w).Encode(models.ParseResponse{Feeds: nil, Errors: []models.ErrorInfo{{URL: "", Message: "invalid request", Code: models.ErrorCodeInvalidRequest, Detail: err.Error()}}})
+		return
+	}
+
+	if err := services.ValidateSanitizePolicy(req.Sanitize); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ParseResponse{Feeds: nil, Errors: []models.ErrorInfo{{URL: "", Message: "invalid request", Code: models.ErrorCodeInvalidRequest, Detail: err.Error()}}})
 		return
 	}
 
 	// Process feeds
-	svc := services.NewRSSService()
-	feeds, errors := svc.ParseFeeds(r.Context(), req.URLs)
-	resp := models.ParseResponse{Feeds: feeds, Errors: errors}
+	// 再試行を含めた全体の処理時間の上限
+	ctx, cancel := context.WithTimeout(r.Context(), services.RequestTimeout)
+	defer cancel()
+	resp := services.SharedService().Parse(ctx, req)
 
 	// Send response
 	w.Header().Set("Content-Type", "application/json")
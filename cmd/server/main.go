@@ -23,7 +23,7 @@ func main() {
 	port := ":8080"
 	logger.Printf("Starting server on port %s", port)
 	logger.Printf("Environment: development (Docker local)")
-	logger.Printf("Endpoints: POST /api/parse, OPTIONS /api/parse")
+	logger.Printf("Endpoints: POST /api/parse, OPTIONS /api/parse, GET /api/diagnostics, GET /api/discover")
 
 	if err := http.ListenAndServe(port, mux); err != nil {
 		logger.Fatalf("Server failed to start: %v", err)
@@ -37,6 +37,16 @@ func SetupRoutes() *http.ServeMux {
 	// /api/parse エンドポイントをCORSミドルウェア付きで登録
 	mux.HandleFunc("/api/parse", corsMiddleware(handler.Handler))
 
+	// /api/diagnostics エンドポイント（サーキットブレーカー等の状態確認）
+	// Vercelと同様に GET /api/parse?diagnostics に書き換え、フィードを取得するハンドラーから状態を返す
+	mux.HandleFunc("/api/diagnostics", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.RawQuery = "diagnostics=1"
+		handler.Handler(w, r)
+	}))
+
+	// /api/discover エンドポイント（HTMLページからのフィード自動検出）
+	mux.HandleFunc("/api/discover", corsMiddleware(handler.DiscoverHandler))
+
 	return mux
 }
 
@@ -65,7 +75,7 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 
 		// CORSヘッダー設定
 		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		// プリフライトOPTIONSリクエストの処理
@@ -39,3 +39,52 @@ func TestOPTIONSRequest(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code, "OPTIONS request should return 200 OK")
 	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"), "OPTIONS should set CORS Origin")
 }
+
+// TestDiagnosticsEndpoint は診断エンドポイントがサーキットブレーカーの状態をJSONで返すことを検証する
+func TestDiagnosticsEndpoint(t *testing.T) {
+	// 準備
+	req := httptest.NewRequest(http.MethodGet, "/api/diagnostics", nil)
+	rec := httptest.NewRecorder()
+
+	// 実行
+	handler := SetupRoutes()
+	handler.ServeHTTP(rec, req)
+
+	// 検証
+	assert.Equal(t, http.StatusOK, rec.Code, "GET /api/diagnostics should return 200 OK")
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "Response should be JSON")
+	assert.Contains(t, rec.Body.String(), "circuitBreakers", "Response should contain circuitBreakers")
+}
+
+// TestParseEndpoint_診断情報 はフィードを取得するハンドラー自体が GET /api/parse?diagnostics で診断情報を返すことを検証する
+// （Vercelでは /api/diagnostics をここに書き換え、/api/parse と同じ関数のインスタンスの状態を返す）
+func TestParseEndpoint_診断情報(t *testing.T) {
+	// 準備
+	req := httptest.NewRequest(http.MethodGet, "/api/parse?diagnostics=1", nil)
+	rec := httptest.NewRecorder()
+
+	// 実行
+	handler := SetupRoutes()
+	handler.ServeHTTP(rec, req)
+
+	// 検証
+	assert.Equal(t, http.StatusOK, rec.Code, "GET /api/parse?diagnostics should return 200 OK")
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "Response should be JSON")
+	assert.Contains(t, rec.Body.String(), "circuitBreakers", "Response should contain circuitBreakers")
+}
+
+// TestDiscoverEndpoint_URL未指定 はurlパラメータが無い場合に400を返すことを検証する
+func TestDiscoverEndpoint_URL未指定(t *testing.T) {
+	// 準備
+	req := httptest.NewRequest(http.MethodGet, "/api/discover", nil)
+	rec := httptest.NewRecorder()
+
+	// 実行
+	handler := SetupRoutes()
+	handler.ServeHTTP(rec, req)
+
+	// 検証
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "GET /api/discover without url should return 400")
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "Response should be JSON")
+	assert.Contains(t, rec.Body.String(), "invalid_request", "Response should contain error code")
+}
@@ -3,12 +3,14 @@ module feed-parallel-parse-api
 go 1.25.1
 
 require (
+	github.com/PuerkitoBio/goquery v1.8.0
 	github.com/mmcdole/gofeed v1.3.0
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/net v0.4.0
+	golang.org/x/text v0.5.0
 )
 
 require (
-	github.com/PuerkitoBio/goquery v1.8.0 // indirect
 	github.com/andybalholm/cascadia v1.3.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
@@ -16,7 +18,5 @@ require (
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/net v0.4.0 // indirect
-	golang.org/x/text v0.5.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
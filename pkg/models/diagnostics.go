@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Diagnostics は診断エンドポイントのレスポンス
+type Diagnostics struct {
+	CircuitBreakers []CircuitBreakerStatus `json:"circuitBreakers"`
+}
+
+// CircuitBreakerStatus はホストごとのサーキットブレーカーの状態
+type CircuitBreakerStatus struct {
+	Host                string     `json:"host"`
+	State               string     `json:"state"` // closed / open / half-open
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	// RetryAfter はハーフオープンに移行するまでの秒数（オープン中のみ）
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
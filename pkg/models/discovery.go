@@ -0,0 +1,23 @@
+package models
+
+// FeedCandidate.Sourceに設定される検出元
+const (
+	FeedSourceSelf = "self" // 指定したURL自体がフィード
+	FeedSourceLink = "link" // HTMLの<link rel="alternate">で宣言されていた
+	FeedSourcePath = "path" // 一般的なパス（/feed、/rss.xml等）で見つかった
+)
+
+// DiscoverResponse はフィード自動検出エンドポイントのレスポンス
+type DiscoverResponse struct {
+	URL   string          `json:"url"`
+	Feeds []FeedCandidate `json:"feeds"` // 見つからない場合は空配列
+	Error *ErrorInfo      `json:"error,omitempty"`
+}
+
+// FeedCandidate はHTMLページから検出したフィードの候補
+type FeedCandidate struct {
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Type   string `json:"type,omitempty"` // application/rss+xml等のメディアタイプ
+	Source string `json:"source"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// FetchInfo はフィード取得時のHTTP通信の計測情報（オリジンへ問い合わせた場合のみ付与）
+type FetchInfo struct {
+	StatusCode  int    `json:"statusCode,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// Bytes は読み取ったボディのサイズ（展開後）
+	Bytes int64 `json:"bytes"`
+	// CompressedBytes は転送時のボディのサイズ（gzip圧縮されていた場合のみ）
+	CompressedBytes int64 `json:"compressedBytes,omitempty"`
+	// FinalURL はリダイレクトを辿った後のURL
+	FinalURL  string       `json:"finalUrl"`
+	Redirects []Redirect   `json:"redirects,omitempty"`
+	Timings   FetchTimings `json:"timings"`
+}
+
+// Redirect はリダイレクトを返したURLとそのステータスコード
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// FetchTimings は取得処理の各段階の所要時間（ミリ秒）
+// リダイレクトや再接続でDNS解決・接続が複数回行われた場合は合計値となる
+type FetchTimings struct {
+	DNS     float64 `json:"dnsMs"`
+	Connect float64 `json:"connectMs"`
+	TLS     float64 `json:"tlsMs"`
+	// TTFB は最後のリクエスト開始から最初のレスポンスバイトまでの時間
+	TTFB  float64 `json:"ttfbMs"`
+	Total float64 `json:"totalMs"`
+}
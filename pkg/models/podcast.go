@@ -0,0 +1,54 @@
+package models
+
+// Podcast はポッドキャストのフィード情報（iTunes・Podcasting 2.0拡張から取得）
+type Podcast struct {
+	Author string  `json:"author,omitempty"`
+	Owner  *Author `json:"owner,omitempty"`
+	// Categories はiTunesのカテゴリー（サブカテゴリーは"Technology > Tech News"の形式）
+	Categories []string `json:"categories,omitempty"`
+	// Explicit は成人向けの内容を含むか（フィードに記載が無い場合は省略）
+	Explicit *bool  `json:"explicit,omitempty"`
+	Image    string `json:"image,omitempty"`
+	// Type は配信形式（episodic: 新しい順、serial: 古い順に聴く連続もの）
+	Type string `json:"type,omitempty"`
+	// Complete は今後エピソードが追加されないか
+	Complete bool `json:"complete,omitempty"`
+	// NewFeedURL はフィードの移転先URL（itunes:new-feed-url）
+	NewFeedURL string           `json:"newFeedUrl,omitempty"`
+	GUID       string           `json:"guid,omitempty"` // podcast:guid
+	Funding    []PodcastFunding `json:"funding,omitempty"`
+}
+
+// PodcastEpisode はポッドキャストのエピソード情報（iTunes・Podcasting 2.0拡張から取得）
+type PodcastEpisode struct {
+	DurationInSeconds float64 `json:"durationInSeconds,omitempty"`
+	Episode           int     `json:"episode,omitempty"`
+	Season            int     `json:"season,omitempty"`
+	// EpisodeType はエピソードの種類（full/trailer/bonus）
+	EpisodeType string              `json:"episodeType,omitempty"`
+	Explicit    *bool               `json:"explicit,omitempty"`
+	Image       string              `json:"image,omitempty"`
+	Transcripts []PodcastTranscript `json:"transcripts,omitempty"`
+	Chapters    *PodcastChapters    `json:"chapters,omitempty"`
+	Funding     []PodcastFunding    `json:"funding,omitempty"`
+}
+
+// PodcastTranscript はエピソードの文字起こし（podcast:transcript）
+type PodcastTranscript struct {
+	URL      string `json:"url"`
+	Type     string `json:"type,omitempty"`
+	Language string `json:"language,omitempty"`
+	Rel      string `json:"rel,omitempty"` // "captions"の場合は字幕として使える
+}
+
+// PodcastChapters はエピソードのチャプター情報（podcast:chapters）
+type PodcastChapters struct {
+	URL  string `json:"url"`
+	Type string `json:"type,omitempty"`
+}
+
+// PodcastFunding は支援・寄付の案内（podcast:funding）
+type PodcastFunding struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
@@ -6,29 +6,181 @@ type RSSFeed struct {
 	Link     string    `json:"link"`
 	FeedURL  string    `json:"feedUrl"` // 実際のRSSフィードURL（v1.1.0で追加）
 	Articles []Article `json:"articles"`
+	// Description/Language/Image/Authors はフィード自体のメタデータ（フィードに含まれる場合のみ）
+	Description string   `json:"description,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Image       string   `json:"image,omitempty"` // フィードのアイコン・ロゴ画像のURL
+	Authors     []Author `json:"authors,omitempty"`
+	// Podcast はポッドキャストのフィード情報（iTunes・Podcasting 2.0拡張がある場合のみ）
+	Podcast *Podcast `json:"podcast,omitempty"`
+	// ETag/LastModified は次回の条件付きGETに使うバリデータ（サーバーが返した場合のみ）
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	// Cache はサーバー側キャッシュの利用状況（hit/stale/revalidated/miss、キャッシュ無効時は省略）
+	Cache string `json:"cache,omitempty"`
+	// Attempts はオリジンへの試行回数（再試行を含む、キャッシュから返した場合は省略）
+	Attempts int `json:"attempts,omitempty"`
+	// FetchInfo はオリジンとのHTTP通信の計測情報（キャッシュから返した場合は省略）
+	FetchInfo *FetchInfo `json:"fetchInfo,omitempty"`
+	// MovedTo はすべて恒久的なリダイレクト（301/308）で移転していた場合の移転先URL（購読URLの更新に使う）
+	MovedTo string `json:"movedTo,omitempty"`
+	// DiscoveredFrom はHTMLページのURLが指定され、そこから自動検出したフィードを返した場合の元のページURL
+	DiscoveredFrom string `json:"discoveredFrom,omitempty"`
+	// Repaired は不正なXMLを修復してからパースした場合の修復内容（Repair*定数、修復していない場合は省略）
+	Repaired []string `json:"repaired,omitempty"`
 }
 
+// RSSFeed.Repairedに設定される修復内容
+const (
+	RepairInvalidCharacters  = "invalid_characters"  // XMLで使えない制御文字・不正なバイト列を除去した
+	RepairUnescapedAmpersand = "unescaped_ampersand" // 実体参照になっていない"&"を"&amp;"にエスケープした
+	RepairTrailingGarbage    = "trailing_garbage"    // ルート要素の後ろの不要な内容を切り捨てた
+)
+
 // Article represents a single article in an RSS feed
 type Article struct {
 	Title   string `json:"title"`
 	Link    string `json:"link"`
 	PubDate string `json:"pubDate"`
 	Summary string `json:"summary"`
+	// SanitizedSummary/SanitizedContentHTML はSummary/ContentHTMLをリクエストのサニタイズ方針で無害化したHTML
+	// （相対URLは絶対URLに解決済み。元のHTMLを含むフィールドは互換性のためそのまま返す）
+	SanitizedSummary     string `json:"sanitizedSummary,omitempty"`
+	SanitizedContentHTML string `json:"sanitizedContentHtml,omitempty"`
+	// Excerpt は一覧表示用の抜粋（タグを除去したプレーンテキストを、リクエストのExcerptLength文字以内に切り詰めたもの）
+	Excerpt string `json:"excerpt,omitempty"`
+	// ID は記事の一意な識別子（RSSのguid、Atomのid、JSON Feedのid。無い場合はリンク）
+	ID string `json:"id,omitempty"`
+	// Updated は最終更新日時（フィードに記載された文字列のまま）
+	Updated string `json:"updated,omitempty"`
+	// PublishedAt/UpdatedAt はPubDate/UpdatedをRFC 3339（UTC）に正規化した日時（解釈できない場合は省略）
+	PublishedAt string `json:"publishedAt,omitempty"`
+	UpdatedAt   string `json:"updatedAt,omitempty"`
+	// ContentHTML/ContentText は記事の本文（Summaryより詳細な場合がある）
+	ContentHTML string `json:"contentHtml,omitempty"`
+	ContentText string `json:"contentText,omitempty"`
+	// Image は記事のメイン画像、BannerImage は横長のバナー画像のURL
+	Image       string `json:"image,omitempty"`
+	BannerImage string `json:"bannerImage,omitempty"`
+	// ImageURL は一覧表示用のサムネイル画像の絶対URL（ImageWidth/ImageHeightは分かる場合のみ）
+	ImageURL    string       `json:"imageUrl,omitempty"`
+	ImageWidth  int          `json:"imageWidth,omitempty"`
+	ImageHeight int          `json:"imageHeight,omitempty"`
+	Authors     []Author     `json:"authors,omitempty"`
+	Categories  []string     `json:"categories,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"` // RSSのenclosure、Atomのrel="enclosure"リンク等
+	// Podcast はポッドキャストのエピソード情報（iTunes・Podcasting 2.0拡張がある場合のみ）
+	Podcast *PodcastEpisode `json:"podcast,omitempty"`
+}
+
+// Author は記事・フィードの著者
+type Author struct {
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+// Attachment は記事に添付されたファイル（音声・動画等）
+type Attachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mimeType,omitempty"`
+	Title    string `json:"title,omitempty"`
+	// SizeInBytes はファイルサイズ、DurationInSeconds は再生時間（分かる場合のみ）
+	SizeInBytes       int64   `json:"sizeInBytes,omitempty"`
+	DurationInSeconds float64 `json:"durationInSeconds,omitempty"`
 }
 
 // ParseRequest is the request payload for parsing RSS feeds
 type ParseRequest struct {
 	URLs []string `json:"urls"`
+	// MaxConcurrency はこのリクエストでの同時取得数の上限（0はサービスのデフォルト、サービス上限を超える値は切り詰め）
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+	// Validators は前回取得時のETag/Last-Modified（条件付きGETに使用）
+	Validators []FeedValidator `json:"validators,omitempty"`
+	// Sanitize は記事のHTMLのサニタイズ方針（strip-all/safe-basic/safe-rich、省略時はsafe-basic）
+	Sanitize string `json:"sanitize,omitempty"`
+	// ExcerptLength は記事の抜粋の最大文字数（書記素クラスタ数。0はデフォルト、1は2として扱い、上限を超える値は切り詰め）
+	ExcerptLength int `json:"excerptLength,omitempty"`
+}
+
+// ParseRequest.Sanitizeに指定できるサニタイズ方針
+const (
+	SanitizeStripAll  = "strip-all"  // タグをすべて除去しテキストのみにする
+	SanitizeSafeBasic = "safe-basic" // 段落・強調・リンク・リスト等の基本的な書式のみ残す
+	SanitizeSafeRich  = "safe-rich"  // 基本的な書式に加え見出し・画像・表等を残す
+)
+
+// FeedValidator はフィードURLごとの条件付きGET用バリデータ
+type FeedValidator struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
 }
 
 // ParseResponse is the response payload after parsing RSS feeds
 type ParseResponse struct {
-	Feeds  []RSSFeed  `json:"feeds"`
+	Feeds  []RSSFeed   `json:"feeds"`
 	Errors []ErrorInfo `json:"errors"`
+	// Results はリクエストのURLと同じ順序・件数の結果一覧（重複URLもそれぞれ1件）
+	Results []FeedResult `json:"results"`
+	// NotModified は304 Not Modifiedが返され、前回から変更のないフィード
+	NotModified []FeedValidator `json:"notModified,omitempty"`
+}
+
+// FeedResult.Statusに設定される値
+const (
+	ResultStatusOK          = "ok"          // フィードを取得できた
+	ResultStatusError       = "error"       // 取得・パースに失敗した
+	ResultStatusNotModified = "notModified" // 前回から変更なし
+)
+
+// FeedResult はリクエストされたURL1件に対する結果
+type FeedResult struct {
+	RequestedURL string         `json:"requestedUrl"`
+	Status       string         `json:"status"`
+	Feed         *RSSFeed       `json:"feed,omitempty"`      // status=okの場合
+	Error        *ErrorInfo     `json:"error,omitempty"`     // status=errorの場合
+	Validator    *FeedValidator `json:"validator,omitempty"` // status=notModifiedの場合
+	// MovedTo はフィードが恒久的に移転していた場合の移転先URL（okまたはnotModifiedの場合）
+	MovedTo string `json:"movedTo,omitempty"`
 }
 
+// ErrorInfo.Codeに設定されるエラーコード（クライアントが表示や再試行の判断に使う安定した値）
+const (
+	ErrorCodeInvalidRequest   = "invalid_request"   // リクエストボディが不正
+	ErrorCodeInvalidURL       = "invalid_url"       // URLが空、またはhttp/httpsの有効なURLでない
+	ErrorCodeDNSFailure       = "dns_failure"       // ホスト名を解決できない
+	ErrorCodeTimeout          = "timeout"           // タイムアウト
+	ErrorCodeConnectionFailed = "connection_failed" // 接続できない・接続が切断された
+	ErrorCodeNetwork          = "network_error"     // その他の通信エラー
+	ErrorCodeRedirectLimit    = "redirect_limit"    // リダイレクト回数の上限超過
+	ErrorCodeHTTPStatus       = "http_status"       // 200以外のHTTPステータス（HTTPStatusに値を設定）
+	ErrorCodeRateLimited      = "rate_limited"      // ホストがレート制限中のため取得を見送った
+	ErrorCodeCircuitOpen      = "circuit_open"      // 連続して失敗しているホストへのリクエストを停止中
+	ErrorCodeBlocked          = "blocked"           // SSRF対策により内部ネットワークへの接続を拒否した
+	ErrorCodeTooLarge         = "too_large"         // フィード本体が最大サイズを超えた
+	ErrorCodeReadError        = "read_error"        // レスポンスボディの読み取りに失敗した
+	ErrorCodeParseError       = "parse_error"       // フィードとしてパースできない
+	ErrorCodeCanceled         = "canceled"          // リクエストがキャンセルされた
+)
+
 // ErrorInfo contains error details for a failed RSS fetch/parse
 type ErrorInfo struct {
 	URL     string `json:"url"`
 	Message string `json:"message"`
+	// Code は機械判定用のエラーコード（ErrorCode*定数のいずれか）
+	Code string `json:"code,omitempty"`
+	// HTTPStatus はオリジンが返したHTTPステータスコード（code=http_statusの場合）
+	HTTPStatus int `json:"httpStatus,omitempty"`
+	// Retryable は時間をおいて再試行すれば成功しうるかどうか
+	Retryable bool `json:"retryable"`
+	// Detail は原因となったエラーの詳細（ログ・デバッグ用、表示には使わないこと）
+	Detail string `json:"detail,omitempty"`
+	// RetryAfter は再試行まで待つべき秒数（429/503のRetry-Afterやレート制限中の場合のみ）
+	RetryAfter int `json:"retryAfter,omitempty"`
+	// Attempts はオリジンへの試行回数（再試行を含む）
+	Attempts int `json:"attempts,omitempty"`
+	// FetchInfo はオリジンとのHTTP通信の計測情報（通信前に失敗した場合は省略）
+	FetchInfo *FetchInfo `json:"fetchInfo,omitempty"`
 }
@@ -0,0 +1,33 @@
+package services
+
+import "feed-parallel-parse-api/pkg/models"
+
+// articleOptions はリクエストごとに記事へ適用する加工の設定
+type articleOptions struct {
+	sanitize      *sanitizePolicy
+	excerptLength int
+}
+
+// newArticleOptions はリクエストの指定から記事の加工の設定を作る（省略・範囲外の値はデフォルトを使う）
+func newArticleOptions(req models.ParseRequest) articleOptions {
+	return articleOptions{
+		sanitize:      lookupSanitizePolicy(req.Sanitize),
+		excerptLength: excerptLength(req.ExcerptLength),
+	}
+}
+
+// transformFeed はリクエストごとの記事の加工（サニタイズ・抜粋）を適用したフィードのコピーを返す
+// 取得結果はキャッシュ・singleflightで他のリクエストと共有されるため、元のフィードは変更しない
+func transformFeed(feed *models.RSSFeed, opts articleOptions) *models.RSSFeed {
+	copied := *feed
+	copied.Articles = make([]models.Article, len(feed.Articles))
+	siteURL := resolveURL(feed.FeedURL, feed.Link)
+	for i, a := range feed.Articles {
+		base := resolveURL(siteURL, a.Link)
+		a.SanitizedSummary = opts.sanitize.sanitize(a.Summary, base)
+		a.SanitizedContentHTML = opts.sanitize.sanitize(a.ContentHTML, base)
+		a.Excerpt = articleExcerpt(a.Summary, a.ContentText, a.ContentHTML, opts.excerptLength)
+		copied.Articles[i] = a
+	}
+	return &copied
+}
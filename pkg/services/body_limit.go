@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"io"
+)
+
+// DefaultMaxFeedSize はフィード本体の最大サイズのデフォルト値（10MiB）
+const DefaultMaxFeedSize int64 = 10 << 20
+
+// feedTooLargeError はフィード本体が最大サイズを超えたことを表す
+type feedTooLargeError struct {
+	limit int64
+}
+
+func (e *feedTooLargeError) Error() string {
+	return fmt.Sprintf("フィードのサイズが上限 %d バイトを超えています", e.limit)
+}
+
+// limitedBody はlimitバイトを超えるデータを読もうとした時点でfeedTooLargeErrorを返すReader
+// io.LimitReaderと異なり、ちょうど上限サイズのボディと上限超過を区別できる
+type limitedBody struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+	exceeded  bool
+	readErr   error // 下位のReaderで発生したEOF以外のエラー（パーサーが握りつぶしても判別できるよう保持）
+}
+
+func newLimitedBody(r io.Reader, limit int64) *limitedBody {
+	return &limitedBody{r: r, limit: limit, remaining: limit}
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, &feedTooLargeError{limit: l.limit}
+	}
+	if l.remaining <= 0 {
+		// 上限に到達したので、まだ続きがあるかを1バイトだけ読んで確認する
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			l.exceeded = true
+			return 0, &feedTooLargeError{limit: l.limit}
+		}
+		return 0, l.record(err)
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, l.record(err)
+}
+
+func (l *limitedBody) record(err error) error {
+	if err != nil && err != io.EOF && l.readErr == nil {
+		l.readErr = err
+	}
+	return err
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+)
+
+const (
+	// DefaultCacheMinTTL はキャッシュの鮮度期間の下限（オリジンの指定がない場合もこの値を使う）
+	DefaultCacheMinTTL = time.Minute
+	// DefaultCacheMaxTTL はキャッシュの鮮度期間の上限
+	DefaultCacheMaxTTL = time.Hour
+	// DefaultCacheSize はハンドラーが使うインメモリLRUキャッシュの最大エントリ数
+	DefaultCacheSize = 1000
+)
+
+// キャッシュ状態（RSSFeed.Cacheに設定される値）
+const (
+	CacheStatusHit         = "hit"         // 鮮度期間内のキャッシュを返した
+	CacheStatusStale       = "stale"       // 期限切れのキャッシュを返し、バックグラウンドで再検証中
+	CacheStatusRevalidated = "revalidated" // オリジンに304を確認してキャッシュを返した
+	CacheStatusMiss        = "miss"        // オリジンから取得した
+)
+
+// CacheEntry はキャッシュされたフィードと鮮度情報
+type CacheEntry struct {
+	Feed       models.RSSFeed
+	StoredAt   time.Time
+	ExpiresAt  time.Time // この時刻まではオリジンに問い合わせずに返す
+	StaleUntil time.Time // この時刻までは期限切れでも返しつつバックグラウンドで再検証する
+}
+
+// FeedCache はフィードURLをキーとするキャッシュのインターフェース
+// 実装はゴルーチンセーフであること
+type FeedCache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// LRUCache は最大エントリ数を超えると最も古く参照されたエントリを破棄するインメモリキャッシュ
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewLRUCache は最大capacity件を保持するLRUCacheを作成する
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (c *LRUCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruItem{key: key, entry: entry})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+// Len は現在のエントリ数を返す
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// cachePolicy はオリジンのCache-Control/Expiresから鮮度期間を決定する
+type cachePolicy struct {
+	minTTL time.Duration
+	maxTTL time.Duration
+	swr    time.Duration // オリジンが指定しない場合のstale-while-revalidate期間
+}
+
+// freshness はレスポンスヘッダーから鮮度期間とstale-while-revalidate期間を求める
+// no-store/privateの場合はキャッシュ不可としてok=falseを返す
+func (p cachePolicy) freshness(header http.Header, now time.Time) (ttl, swr time.Duration, ok bool) {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, noStore := directives["no-store"]; noStore {
+		return 0, 0, false
+	}
+	if _, private := directives["private"]; private {
+		return 0, 0, false
+	}
+
+	ttl, explicit := originTTL(directives, header, now)
+	if _, noCache := directives["no-cache"]; noCache {
+		// 毎回再検証が必要なため鮮度期間は0（バリデータによる304確認には使う）
+		ttl = 0
+	} else {
+		if !explicit || ttl < p.minTTL {
+			ttl = p.minTTL
+		}
+		if ttl > p.maxTTL {
+			ttl = p.maxTTL
+		}
+	}
+
+	swr = p.swr
+	if v, found := directives["stale-while-revalidate"]; found {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			swr = time.Duration(secs) * time.Second
+		}
+	}
+	if swr > p.maxTTL {
+		swr = p.maxTTL
+	}
+	return ttl, swr, true
+}
+
+// originTTL はs-maxage → max-age → Expiresの優先順位でオリジン指定の鮮度期間を求める
+func originTTL(directives map[string]string, header http.Header, now time.Time) (time.Duration, bool) {
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if v, found := directives[name]; found {
+			secs, err := strconv.Atoi(v)
+			if err != nil {
+				continue
+			}
+			ttl := time.Duration(secs) * time.Second
+			if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+				ttl -= time.Duration(age) * time.Second
+			}
+			if ttl < 0 {
+				ttl = 0
+			}
+			return ttl, true
+		}
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			// 不正なExpiresは期限切れとして扱う（RFC 9111）
+			return 0, true
+		}
+		base := now
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			base = date
+		}
+		if ttl := t.Sub(base); ttl > 0 {
+			return ttl, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// parseCacheControl はCache-Controlヘッダーをディレクティブ名（小文字）→値のマップに変換する
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, v, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(v), `"`)
+	}
+	return directives
+}
+
+// loadFeed はキャッシュを考慮してフィードを取得する
+// 鮮度期間内ならキャッシュを返し、stale-while-revalidate期間内なら期限切れのキャッシュを返しつつ
+// バックグラウンドで再検証する。それ以外はキャッシュのバリデータを使って条件付きGETを行う
+func (s *RSSService) loadFeed(ctx context.Context, u string, validator models.FeedValidator) feedResult {
+	if s.cache == nil || u == "" {
+		return s.fetchShared(ctx, u, validator)
+	}
+
+	entry, cached := s.cache.Get(u)
+	now := time.Now()
+	if cached && now.Before(entry.ExpiresAt) {
+		return cachedResult(u, entry, validator, CacheStatusHit)
+	}
+	if cached && now.Before(entry.StaleUntil) {
+		s.revalidateInBackground(u, entry)
+		return cachedResult(u, entry, validator, CacheStatusStale)
+	}
+
+	originValidator := validator
+	if cached {
+		// キャッシュ本体を返せるよう、キャッシュ側のバリデータで問い合わせる
+		originValidator = models.FeedValidator{URL: u, ETag: entry.Feed.ETag, LastModified: entry.Feed.LastModified}
+	}
+	result := s.fetchShared(ctx, u, originValidator)
+	switch {
+	case result.notModified != nil && cached:
+		entry = s.storeFeed(u, entry.Feed, result.header)
+		revalidated := cachedResult(u, entry, validator, CacheStatusRevalidated)
+		if revalidated.feed != nil {
+			revalidated.feed.FetchInfo = result.fetchInfo
+		}
+		return revalidated
+	case result.feed != nil:
+		s.storeFeed(u, *result.feed, result.header)
+		result.feed.Cache = CacheStatusMiss
+	}
+	return result
+}
+
+// storeFeed はレスポンスヘッダーから鮮度を求めてキャッシュに保存する（キャッシュ不可の場合は保存しない）
+func (s *RSSService) storeFeed(u string, feed models.RSSFeed, header http.Header) *CacheEntry {
+	now := time.Now()
+	ttl, swr, ok := s.cachePolicy.freshness(header, now)
+	feed.Cache = ""
+	feed.FetchInfo = nil
+	entry := &CacheEntry{Feed: feed, StoredAt: now, ExpiresAt: now.Add(ttl), StaleUntil: now.Add(ttl + swr)}
+	if ok {
+		s.cache.Set(u, entry)
+	}
+	return entry
+}
+
+// revalidateInBackground は期限切れキャッシュをバックグラウンドで再取得する（同一キーは同時に1件のみ）
+func (s *RSSService) revalidateInBackground(u string, entry *CacheEntry) {
+	if _, running := s.refreshing.LoadOrStore(u, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer s.refreshing.Delete(u)
+		ctx, cancel := context.WithTimeout(context.Background(), s.httpClient.Timeout)
+		defer cancel()
+		result := s.fetchShared(ctx, u, models.FeedValidator{URL: u, ETag: entry.Feed.ETag, LastModified: entry.Feed.LastModified})
+		switch {
+		case result.notModified != nil:
+			s.storeFeed(u, entry.Feed, result.header)
+		case result.feed != nil:
+			s.storeFeed(u, *result.feed, result.header)
+		}
+	}()
+}
+
+// cachedResult はキャッシュエントリから結果を組み立てる
+// クライアントのバリデータがキャッシュと一致する場合はNotModifiedとして返す
+func cachedResult(u string, entry *CacheEntry, validator models.FeedValidator, status string) feedResult {
+	feed := entry.Feed
+	if (validator.ETag != "" && validator.ETag == feed.ETag) ||
+		(validator.ETag == "" && validator.LastModified != "" && validator.LastModified == feed.LastModified) {
+		return feedResult{notModified: &models.FeedValidator{URL: u, ETag: feed.ETag, LastModified: feed.LastModified}, movedTo: feed.MovedTo}
+	}
+	feed.Cache = status
+	return feedResult{feed: &feed, movedTo: feed.MovedTo}
+}
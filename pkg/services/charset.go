@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// xmlDeclPattern は文書先頭のXML宣言（UTF-8のBOMに続くものを含む）
+var xmlDeclPattern = regexp.MustCompile(`^\x{feff}?\s*<\?xml\s[^>]*\?>`)
+
+// xmlDeclEncodingPattern はXML宣言内のencoding属性
+var xmlDeclEncodingPattern = regexp.MustCompile(`encoding\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// byteOrderMarks はBOMと対応する文字コード（BOMは他の指定より優先する）
+var byteOrderMarks = []struct {
+	bom      []byte
+	encoding encoding.Encoding
+}{
+	{[]byte("\xef\xbb\xbf"), unicode.UTF8BOM},
+	{[]byte("\xfe\xff"), unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)},
+	{[]byte("\xff\xfe"), unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)},
+}
+
+// decodeToUTF8 はボディの文字コードを判定し、UTF-8に変換したリーダーを返す
+// 判定の優先順位: BOM → Content-Typeのcharset → XML宣言のencoding → UTF-8
+// ただしContent-TypeがUTF-8でXML宣言が別の文字コードの場合は、ボディ全体がUTF-8として不正ならXML宣言のencodingを使う
+// （サーバーの設定誤りが多く、先頭がASCIIのみのこともあるため、この場合に限りボディ全体を読み取って検査する）
+// 変換後はXML宣言のencodingをUTF-8に書き換え、パーサーが二重に変換しないようにする
+func decodeToUTF8(r *bufio.Reader, contentType string) *bufio.Reader {
+	head, _ := r.Peek(sniffLen)
+	enc := detectEncoding(head, contentType)
+	if enc == nil && contentTypeCharset(contentType) != "" && declaredEncodingOf(head) != nil {
+		data, _ := io.ReadAll(r) // 読み取りエラー・サイズ超過は呼び出し元（limitedBody）が記録する
+		r = bufio.NewReader(bytes.NewReader(data))
+		if !utf8.Valid(data) {
+			enc = declaredEncodingOf(head)
+		}
+	}
+	if enc == nil {
+		if isUTF8Label(declaredEncoding(head)) {
+			return r // UTF-8として読み、XML宣言の書き換えも不要
+		}
+		return rewriteXMLDeclEncoding(r)
+	}
+	return rewriteXMLDeclEncoding(bufio.NewReader(transform.NewReader(r, enc.NewDecoder())))
+}
+
+// detectEncoding はボディの先頭とContent-Typeから文字コードを判定する（UTF-8の場合はnil）
+func detectEncoding(head []byte, contentType string) encoding.Encoding {
+	for _, m := range byteOrderMarks {
+		if bytes.HasPrefix(head, m.bom) {
+			if m.encoding == unicode.UTF8BOM {
+				return nil // BOMはパーサーが読み飛ばす
+			}
+			return m.encoding
+		}
+	}
+	if label := contentTypeCharset(contentType); label != "" {
+		if isUTF8Label(label) {
+			return nil // XML宣言と食い違う場合はdecodeToUTF8がボディ全体で検査する
+		}
+		if enc, _ := charset.Lookup(label); enc != nil {
+			return enc
+		}
+	}
+	return declaredEncodingOf(head)
+}
+
+// declaredEncodingOf はXML宣言のencodingがUTF-8以外の既知の文字コードの場合にその文字コードを返す
+// XML宣言がASCIIとして読める時点でUTF-16ではない（BOMの無いUTF-16の宣言は誤りとして無視する）
+func declaredEncodingOf(head []byte) encoding.Encoding {
+	declared := declaredEncoding(head)
+	if isUTF8Label(declared) || strings.HasPrefix(strings.ToLower(declared), "utf-16") {
+		return nil
+	}
+	enc, _ := charset.Lookup(declared)
+	return enc // 未知の文字コードの場合はnil（UTF-8として読む）
+}
+
+// isUTF8Label は文字コード名がUTF-8（またはその部分集合のASCII、省略）かを返す
+func isUTF8Label(label string) bool {
+	if label == "" {
+		return true
+	}
+	switch strings.ToLower(label) {
+	case "us-ascii", "ascii":
+		return true
+	}
+	_, name := charset.Lookup(label)
+	return name == "utf-8"
+}
+
+// contentTypeCharset はContent-Typeのcharsetパラメータを返す
+func contentTypeCharset(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// declaredEncoding はXML宣言のencodingを返す（宣言が無い場合は空）
+func declaredEncoding(head []byte) string {
+	decl := xmlDeclPattern.Find(head)
+	if decl == nil {
+		return ""
+	}
+	m := xmlDeclEncodingPattern.FindSubmatch(decl)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(m[1]) + string(m[2]))
+}
+
+// rewriteXMLDeclEncoding はUTF-8に変換済みのボディのXML宣言のencodingをUTF-8にする
+func rewriteXMLDeclEncoding(r *bufio.Reader) *bufio.Reader {
+	head, _ := r.Peek(sniffLen)
+	decl := xmlDeclPattern.Find(head)
+	if decl == nil || !xmlDeclEncodingPattern.Match(decl) {
+		return r
+	}
+	rewritten := xmlDeclEncodingPattern.ReplaceAllLiteral(decl, []byte(`encoding="UTF-8"`))
+	r.Discard(len(decl))
+	return bufio.NewReader(io.MultiReader(bytes.NewReader(rewritten), r))
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+)
+
+const (
+	// DefaultBreakerThreshold はサーキットを開くまでの連続失敗回数のデフォルト値
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerOpenTimeout はサーキットを開いてから復旧確認（ハーフオープン）までの時間のデフォルト値
+	DefaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// サーキットの状態
+const (
+	BreakerClosed   = "closed"    // 通常どおりリクエストする
+	BreakerOpen     = "open"      // 即座に失敗させる
+	BreakerHalfOpen = "half-open" // 復旧確認のため1件だけリクエストする
+)
+
+// circuitOpenError はサーキットが開いているためリクエストしなかったことを表す
+type circuitOpenError struct {
+	host       string
+	retryAfter time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("ホスト %s は連続して失敗しているため取得を一時停止しています（%d秒後に再開）", e.host, retryAfterSeconds(e.retryAfter))
+}
+
+// circuitBreaker はホスト単位で連続失敗を数え、閾値に達したホストへのリクエストを一時停止する
+type circuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*breakerHost
+}
+
+// breakerHost は1ホスト分のサーキット状態
+type breakerHost struct {
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool // ハーフオープン中の確認リクエストが実行中
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, openTimeout: openTimeout, hosts: make(map[string]*breakerHost)}
+}
+
+// allow はホストへのリクエスト可否を判定する
+// オープン状態で一定時間が経過していれば、ハーフオープンに移行して1件だけ許可する
+func (b *circuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h, ok := b.hosts[host]
+	if !ok {
+		return nil
+	}
+	switch h.state {
+	case BreakerOpen:
+		if wait := b.openTimeout - time.Since(h.openedAt); wait > 0 {
+			return &circuitOpenError{host: host, retryAfter: wait}
+		}
+		h.state = BreakerHalfOpen
+		h.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if h.probing {
+			return &circuitOpenError{host: host, retryAfter: time.Second}
+		}
+		h.probing = true
+	}
+	return nil
+}
+
+// success はホストが応答したことを記録し、サーキットを閉じる
+func (b *circuitBreaker) success(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.hosts, host)
+}
+
+// failure はホストへのリクエスト失敗を記録する
+// ハーフオープン中の失敗、または連続失敗が閾値に達した場合はサーキットを開く
+func (b *circuitBreaker) failure(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h, ok := b.hosts[host]
+	if !ok {
+		h = &breakerHost{state: BreakerClosed}
+		b.hosts[host] = h
+	}
+	h.failures++
+	h.probing = false
+	if h.state == BreakerHalfOpen || h.failures >= b.threshold {
+		h.state = BreakerOpen
+		h.openedAt = time.Now()
+	}
+}
+
+// abandon はハーフオープン中の確認リクエストが結果を得られずに終わったことを記録する
+// （呼び出し元のキャンセル等）。次のリクエストが改めて確認を行う
+func (b *circuitBreaker) abandon(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if h, ok := b.hosts[host]; ok {
+		h.probing = false
+	}
+}
+
+// snapshot は失敗を記録しているホストのサーキット状態をホスト名順に返す
+func (b *circuitBreaker) snapshot() []models.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	statuses := make([]models.CircuitBreakerStatus, 0, len(b.hosts))
+	for host, h := range b.hosts {
+		status := models.CircuitBreakerStatus{Host: host, State: h.state, ConsecutiveFailures: h.failures}
+		if h.state == BreakerOpen {
+			opened := h.openedAt.UTC()
+			status.OpenedAt = &opened
+			if wait := b.openTimeout - time.Since(h.openedAt); wait > 0 {
+				status.RetryAfter = retryAfterSeconds(wait)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Host < statuses[j].Host })
+	return statuses
+}
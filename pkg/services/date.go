@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/width"
+)
+
+// jst は日本語の日時表記にタイムゾーンが無い場合に仮定する日本標準時
+var jst = time.FixedZone("JST", 9*60*60)
+
+// zoneOffsets はフィードで使われるタイムゾーン略称のUTCからのオフセット（秒）
+// time.Parseは未知の略称をオフセット0として解釈するため、既知の略称は数値に置き換えてから解釈する
+var zoneOffsets = map[string]int{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0,
+	"JST": 9 * 3600, "KST": 9 * 3600,
+	"EST": -5 * 3600, "EDT": -4 * 3600,
+	"CST": -6 * 3600, "CDT": -5 * 3600,
+	"MST": -7 * 3600, "MDT": -6 * 3600,
+	"PST": -8 * 3600, "PDT": -7 * 3600,
+	"CET": 1 * 3600, "CEST": 2 * 3600,
+	"BST": 1 * 3600,
+}
+
+// dateLayouts はタイムゾーンの数値表記を含む日時の書式（曜日・タイムゾーン略称は事前に除去・置換する）
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04:05 -0700",
+	"2006-1-2T15:04:05Z07:00",
+	"2006-1-2T15:04:05Z0700",
+	"2006-1-2T15:04:05Z07",
+	"2006-1-2T15:04Z07:00",
+	"2006-1-2 15:04:05Z07:00",
+	"2006-1-2 15:04:05 -0700",
+	"2006-1-2 15:04:05 -07:00",
+	"2006/1/2 15:04:05 -0700",
+}
+
+// localDateLayouts はタイムゾーンを含まない日時の書式（UTCとして解釈する）
+var localDateLayouts = []string{
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2006-1-2T15:04:05",
+	"2006-1-2T15:04",
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2 15:04:05",
+	"2006.1.2",
+}
+
+var (
+	// leadingWeekdayPattern は先頭の曜日（"Tue, "や"Tues "等。綴りの誤りがあっても除去する）
+	leadingWeekdayPattern = regexp.MustCompile(`^(?i:mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+`)
+	// trailingCommentPattern は末尾の括弧書き（"+0900 (JST)"の"(JST)"等）
+	trailingCommentPattern = regexp.MustCompile(`\s*\([^)]*\)$`)
+	// trailingZonePattern は末尾のタイムゾーン略称
+	trailingZonePattern = regexp.MustCompile(`\s+([A-Z]{1,4})$`)
+	// japaneseDatePattern は日本語の日時表記（"2024年1月2日(火) 午後9時05分"、"2024年01月02日 21:05:00"等）
+	japaneseDatePattern = regexp.MustCompile(`^(\d{4})年\s*(\d{1,2})月\s*(\d{1,2})日\s*(?:[(（][^)）]*[)）])?\s*(?:(午前|午後)?\s*(\d{1,2})(?:時|:)\s*(\d{1,2})分?(?:(?::|\s*)(\d{1,2})秒?)?)?$`)
+)
+
+// normalizeDate はフィードに記載された日時をRFC 3339（UTC）の文字列に正規化する
+// parsedはgofeedが解釈した日時（無い場合はnil）で、解釈できない場合は空文字を返す
+func normalizeDate(raw string, parsed *time.Time) string {
+	if parsed != nil && !hasZoneAbbreviation(raw) {
+		return parsed.UTC().Format(time.RFC3339)
+	}
+	t, ok := parseDate(raw)
+	if !ok {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// hasZoneAbbreviation はUTC以外のタイムゾーン略称で終わる日時かを返す
+// gofeedはtime.Parseで解釈できない略称をUTCとして扱う（"09:00:00 JST"がUTCの9時になる）ため、自前で解釈し直す
+func hasZoneAbbreviation(raw string) bool {
+	s := trailingCommentPattern.ReplaceAllString(strings.TrimSpace(raw), "")
+	m := trailingZonePattern.FindStringSubmatch(s)
+	return m != nil && zoneOffsets[m[1]] != 0
+}
+
+// parseDate は一般的な書式の崩れ（曜日の誤り・全角数字・タイムゾーン略称・日本語表記等）を許容して日時を解釈する
+func parseDate(raw string) (time.Time, bool) {
+	s := strings.Join(strings.Fields(width.Narrow.String(raw)), " ")
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, ok := parseJapaneseDate(s); ok {
+		return t, true
+	}
+
+	s = trailingCommentPattern.ReplaceAllString(s, "")
+	s = leadingWeekdayPattern.ReplaceAllString(s, "")
+	if m := trailingZonePattern.FindStringSubmatchIndex(s); m != nil {
+		if offset, ok := zoneOffsets[s[m[2]:m[3]]]; ok {
+			s = s[:m[0]] + " " + formatZoneOffset(offset)
+		}
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	for _, layout := range localDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseJapaneseDate は日本語の日時表記を解釈する（タイムゾーンの記載が無いため日本標準時とみなす）
+func parseJapaneseDate(s string) (time.Time, bool) {
+	m := japaneseDatePattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	num := func(v string) int {
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	year, month, day := num(m[1]), num(m[2]), num(m[3])
+	hour, minute, second := num(m[5]), num(m[6]), num(m[7])
+	switch {
+	case m[4] == "午後" && hour < 12:
+		hour += 12
+	case m[4] == "午前" && hour == 12:
+		hour = 0
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, jst)
+	if t.Day() != day {
+		return time.Time{}, false // 2月30日等の存在しない日付
+	}
+	return t, true
+}
+
+// formatZoneOffset はオフセット（秒）を"+0900"形式にする
+func formatZoneOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"feed-parallel-parse-api/pkg/models"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// feedLinkTypes は<link rel="alternate">のうちフィードとして扱うメディアタイプ
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// commonFeedPaths は<link>タグが無い場合に試す一般的なフィードのパス
+var commonFeedPaths = []string{"feed", "rss.xml", "atom.xml", "feed.xml", "index.xml", "rss", "feed.json"}
+
+// htmlPage はフィードの代わりに取得したHTMLページ
+type htmlPage struct {
+	url   string                 // ページのURL（リダイレクト後）
+	links []models.FeedCandidate // <link rel="alternate">で宣言されたフィード
+}
+
+// feedCandidate は検出したフィードの候補と、確認のために取得した結果
+type feedCandidate struct {
+	models.FeedCandidate
+	result *feedResult // パスを試して見つけた場合の取得結果（<link>の候補はnil）
+}
+
+// isHTMLDocument は先頭部分からボディがフィードではなくHTMLページかを判定する
+// text/htmlで配信されるフィードもあるため、Content-Typeではなく内容で判定する
+// JSON（JSON Feed）は記事のcontent_htmlにHTMLを含むことがあるため、HTMLページとはみなさない
+func isHTMLDocument(r *bufio.Reader) bool {
+	head, _ := r.Peek(sniffLen)
+	if isJSONObject(head) {
+		return false
+	}
+	s := strings.ToLower(string(head))
+	for _, root := range []string{"<rss", "<feed", "<rdf:rdf"} {
+		if strings.Contains(s, root) {
+			return false
+		}
+	}
+	return strings.Contains(s, "<!doctype html") || strings.Contains(s, "<html")
+}
+
+// parseHTMLPage はHTMLから<link rel="alternate">で宣言されたフィードを抽出する
+// 相対URLは<base href>、無ければページのURLを基準に解決する
+func parseHTMLPage(r io.Reader, pageURL *url.URL) (*htmlPage, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	base := pageURL
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil {
+			base = u
+		}
+	}
+
+	page := &htmlPage{url: pageURL.String()}
+	seen := make(map[string]bool)
+	doc.Find("link[href]").Each(func(_ int, link *goquery.Selection) {
+		if !hasToken(link.AttrOr("rel", ""), "alternate") {
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(link.AttrOr("type", ""))
+		if err != nil || !feedLinkTypes[mediaType] {
+			return
+		}
+		u, err := base.Parse(strings.TrimSpace(link.AttrOr("href", "")))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || seen[u.String()] {
+			return
+		}
+		seen[u.String()] = true
+		page.links = append(page.links, models.FeedCandidate{
+			URL:    u.String(),
+			Title:  strings.TrimSpace(link.AttrOr("title", "")),
+			Type:   mediaType,
+			Source: models.FeedSourceLink,
+		})
+	})
+	return page, nil
+}
+
+// hasToken は空白区切りの属性値（rel等）に指定したトークンが含まれるかを返す
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// commonFeedURLs はページと同じディレクトリ、およびサイトのルートにある一般的なフィードのURLを返す
+func commonFeedURLs(pageURL string) []string {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	dirs := []string{"/"}
+	if dir := page.Path[:strings.LastIndex(page.Path, "/")+1]; dir != "/" && dir != "" {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	var urls []string
+	for _, dir := range dirs {
+		for _, p := range commonFeedPaths {
+			u := *page
+			u.Path, u.RawPath, u.RawQuery, u.Fragment = dir+p, "", "", ""
+			urls = append(urls, u.String())
+		}
+	}
+	return urls
+}
+
+// feedCandidates はHTMLページからフィードの候補を集める
+// <link>タグが無い場合は一般的なパスを1つずつ取得し、フィードとしてパースできたものを候補とする
+// （推測したパスのためワーカーと同じく1件ずつ取得し、再試行・サーキットブレーカーへの記録は行わない）
+// firstOnlyの場合は最初に見つかった候補で探索をやめる
+func (s *RSSService) feedCandidates(ctx context.Context, page *htmlPage, firstOnly bool) []feedCandidate {
+	if len(page.links) > 0 {
+		candidates := make([]feedCandidate, 0, len(page.links))
+		for _, link := range page.links {
+			candidates = append(candidates, feedCandidate{FeedCandidate: link})
+		}
+		return candidates
+	}
+
+	var candidates []feedCandidate
+	for _, u := range commonFeedURLs(page.url) {
+		if ctx.Err() != nil {
+			break
+		}
+		result := s.fetchOnce(ctx, u, models.FeedValidator{}, true).withAttempts(1)
+		if result.feed == nil {
+			continue
+		}
+		candidates = append(candidates, feedCandidate{
+			FeedCandidate: models.FeedCandidate{
+				URL:    u,
+				Title:  result.feed.Title,
+				Type:   mediaTypeOf(result.fetchInfo),
+				Source: models.FeedSourcePath,
+			},
+			result: &result,
+		})
+		if firstOnly {
+			break
+		}
+	}
+	return candidates
+}
+
+// fetchDiscovering はフィードを取得し、HTMLページだった場合は自動検出した最初のフィードを取得する
+func (s *RSSService) fetchDiscovering(ctx context.Context, u string, validator models.FeedValidator) feedResult {
+	result := s.fetchFeed(ctx, u, validator)
+	if result.page == nil {
+		return result
+	}
+
+	candidates := s.feedCandidates(ctx, result.page, true)
+	if len(candidates) == 0 {
+		info := newErrorInfo(u, models.ErrorCodeParseError, "パース失敗: HTMLページからフィードを検出できませんでした", nil)
+		info.Attempts, info.FetchInfo = result.attempts, result.fetchInfo
+		return feedResult{err: info}
+	}
+
+	best := candidates[0]
+	var followed feedResult
+	if best.result != nil {
+		followed = *best.result
+	} else {
+		followed = s.fetchFeed(ctx, best.URL, validator)
+	}
+	switch {
+	case followed.page != nil:
+		// 検出したURLもHTMLページの場合はそれ以上辿らない
+		info := newErrorInfo(u, models.ErrorCodeParseError, fmt.Sprintf("パース失敗: 自動検出したフィード %s がHTMLページです", best.URL), nil)
+		info.Attempts, info.FetchInfo = followed.attempts, followed.fetchInfo
+		return feedResult{err: info}
+	case followed.err != nil:
+		// エラーはリクエストされたURLで報告する
+		followed.err.URL = u
+		followed.err.Message = fmt.Sprintf("%s（自動検出したフィード: %s）", followed.err.Message, best.URL)
+	case followed.notModified != nil:
+		// クライアントは購読URL（リクエストされたURL）をキーにバリデータを保持するため、リクエストされたURLで返す
+		nm := *followed.notModified
+		nm.URL = u
+		followed.notModified = &nm
+	case followed.feed != nil:
+		followed.feed.DiscoveredFrom = u
+	}
+	return followed
+}
+
+// Discover は指定したURLのページからフィードの候補を検出する
+// URL自体がフィードの場合はそのURLを候補として返す
+func (s *RSSService) Discover(ctx context.Context, pageURL string) models.DiscoverResponse {
+	resp := models.DiscoverResponse{URL: pageURL, Feeds: []models.FeedCandidate{}}
+	result := s.fetchFeed(ctx, pageURL, models.FeedValidator{})
+	switch {
+	case result.err != nil:
+		resp.Error = result.err
+	case result.feed != nil:
+		resp.Feeds = append(resp.Feeds, models.FeedCandidate{
+			URL:    pageURL,
+			Title:  result.feed.Title,
+			Type:   mediaTypeOf(result.fetchInfo),
+			Source: models.FeedSourceSelf,
+		})
+	case result.page != nil:
+		for _, c := range s.feedCandidates(ctx, result.page, false) {
+			resp.Feeds = append(resp.Feeds, c.FeedCandidate)
+		}
+	}
+	return resp
+}
+
+// mediaTypeOf はレスポンスのContent-Typeからパラメータを除いたメディアタイプを返す
+func mediaTypeOf(info *models.FetchInfo) string {
+	if info == nil {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(info.ContentType)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"feed-parallel-parse-api/pkg/models"
+)
+
+// errTooManyRedirects はリダイレクト回数が上限を超えたことを表す
+var errTooManyRedirects = errors.New("リダイレクトが10回を超えました")
+
+// newErrorInfo はエラーコードに応じた再試行可否を設定したErrorInfoを作成する
+// detailには原因となったエラーを渡す（メッセージとは別に機械処理・ログ用に返す）
+func newErrorInfo(u, code, message string, detail error) *models.ErrorInfo {
+	info := &models.ErrorInfo{URL: u, Message: message, Code: code, Retryable: retryableCode(code)}
+	if detail != nil {
+		info.Detail = detail.Error()
+	}
+	return info
+}
+
+// httpStatusErrorInfo はHTTPステータスエラーのErrorInfoを作成する
+func httpStatusErrorInfo(u string, resp *http.Response) *models.ErrorInfo {
+	info := newErrorInfo(u, models.ErrorCodeHTTPStatus, fmt.Sprintf("HTTPエラー: %d %s", resp.StatusCode, resp.Status), nil)
+	info.HTTPStatus = resp.StatusCode
+	info.Retryable = retryableHTTPStatus(resp.StatusCode)
+	return info
+}
+
+// retryableCode はクライアントが時間をおいて再試行すれば成功しうるエラーか判定する
+func retryableCode(code string) bool {
+	switch code {
+	case models.ErrorCodeTimeout, models.ErrorCodeDNSFailure, models.ErrorCodeConnectionFailed,
+		models.ErrorCodeNetwork, models.ErrorCodeReadError, models.ErrorCodeRateLimited, models.ErrorCodeCircuitOpen:
+		return true
+	}
+	return false
+}
+
+// retryableHTTPStatus は時間をおいて再試行すれば成功しうるHTTPステータスか判定する
+func retryableHTTPStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= 500
+}
+
+// classifyFetchError はHTTPクライアント・コンテキストのエラーをエラーコードに分類する
+func classifyFetchError(ctx context.Context, err error) string {
+	var blocked *blockedAddressError
+	var dnsErr *net.DNSError
+	var urlErr *url.Error
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &blocked):
+		return models.ErrorCodeBlocked
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		return models.ErrorCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.ErrorCodeTimeout
+	case errors.Is(err, errTooManyRedirects):
+		return models.ErrorCodeRedirectLimit
+	case errors.As(err, &dnsErr):
+		return models.ErrorCodeDNSFailure
+	case errors.As(err, &urlErr) && urlErr.Timeout():
+		return models.ErrorCodeTimeout
+	case errors.As(err, &opErr):
+		return models.ErrorCodeConnectionFailed
+	}
+	return models.ErrorCodeNetwork
+}
+
+// validateFeedURL は取得対象として有効なhttp/https URLか検査する
+func validateFeedURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("サポートされていないスキームです: %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return errors.New("ホストが指定されていません")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultExcerptLength は抜粋の長さ（書記素クラスタ数）のデフォルト
+const DefaultExcerptLength = 120
+
+// MaxExcerptLength は抜粋の長さの上限（これを超える指定は切り詰め）
+const MaxExcerptLength = 1000
+
+// MinExcerptLength は抜粋の長さの下限（省略記号の前に少なくとも1文字を残す）
+const MinExcerptLength = 2
+
+// excerptEllipsis は切り詰めた抜粋の末尾に付ける省略記号
+const excerptEllipsis = "…"
+
+// zeroWidthJoiner は絵文字を結合するゼロ幅接合子（ZWJ）
+const zeroWidthJoiner = '\u200d'
+
+// excerptLength はリクエストで指定された抜粋の長さを返す（0以下はデフォルト、下限・上限の範囲外の値は範囲内に収める）
+func excerptLength(requested int) int {
+	switch {
+	case requested <= 0:
+		return DefaultExcerptLength
+	case requested < MinExcerptLength:
+		return MinExcerptLength
+	case requested > MaxExcerptLength:
+		return MaxExcerptLength
+	}
+	return requested
+}
+
+// articleExcerpt は記事の要約（無ければ本文）からプレーンテキストの抜粋を作る
+func articleExcerpt(summary, contentText, contentHTML string, length int) string {
+	text := plainText(summary)
+	if text == "" {
+		text = collapseSpace(contentText)
+	}
+	if text == "" {
+		text = plainText(contentHTML)
+	}
+	return truncateGraphemes(text, length)
+}
+
+// truncateGraphemes はテキストを書記素クラスタ単位でlength以内に切り詰める
+// 結合文字・異体字セレクタ・ZWJで結合した絵文字・国旗等を途中で分割せず、切り詰めた場合は末尾を省略記号にする
+// 英単語の途中で切れる場合は直前の空白まで戻す（日本語のように空白の無いテキストはそのまま切る）
+// lengthはMinExcerptLength以上（省略記号の前に少なくとも1文字を残す）
+func truncateGraphemes(text string, length int) string {
+	length = max(length, MinExcerptLength)
+	var boundaries []int // 各書記素クラスタの終端のバイト位置
+	for i := 0; i < len(text); {
+		i += graphemeLen(text[i:])
+		boundaries = append(boundaries, i)
+		if len(boundaries) > length {
+			break
+		}
+	}
+	if len(boundaries) <= length {
+		return text
+	}
+
+	cut := boundaries[length-2] // 省略記号の分を1文字空ける
+	if isWordRune(lastRune(text[:cut])) && isWordRune(firstRune(text[cut:])) {
+		if space := strings.LastIndexByte(text[:cut], ' '); space > cut/2 {
+			cut = space
+		}
+	}
+	return strings.TrimRight(text[:cut], " ") + excerptEllipsis
+}
+
+// graphemeLen は先頭の書記素クラスタのバイト数を返す（Unicodeの拡張書記素クラスタの主な規則を簡略化したもの）
+func graphemeLen(s string) int {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == '\r' && strings.HasPrefix(s[n:], "\n") {
+		return n + 1
+	}
+	if isRegionalIndicator(r) {
+		// 国旗は地域指示記号の2文字で1つ
+		if next, m := utf8.DecodeRuneInString(s[n:]); isRegionalIndicator(next) {
+			return n + m
+		}
+		return n
+	}
+	for prev := r; n < len(s); {
+		next, m := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case isGraphemeExtend(next), joinsHangul(prev, next):
+			n += m
+		case next == zeroWidthJoiner:
+			n += m
+			// ZWJの次の文字（絵文字）も同じクラスタに含める
+			if n < len(s) {
+				next, m = utf8.DecodeRuneInString(s[n:])
+				n += m
+			}
+		default:
+			return n
+		}
+		prev = next
+	}
+	return n
+}
+
+// hangulType はハングルの音節を構成する文字の種類（UnicodeのHangul_Syllable_Type）
+type hangulType int
+
+const (
+	hangulNone hangulType = iota
+	hangulL               // 初声の字母
+	hangulV               // 中声の字母
+	hangulT               // 終声の字母
+	hangulLV              // 終声の無い音節
+	hangulLVT             // 終声のある音節
+)
+
+// hangulTypeOf は文字のハングルの音節の種類を返す
+func hangulTypeOf(r rune) hangulType {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return hangulL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return hangulV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return hangulT
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	}
+	return hangulNone
+}
+
+// joinsHangul は字母に分解されたハングル（NFD等）で、nextがprevと同じ音節になるかを返す（UAX #29のGB6〜GB8）
+func joinsHangul(prev, next rune) bool {
+	switch p, q := hangulTypeOf(prev), hangulTypeOf(next); p {
+	case hangulL:
+		return q == hangulL || q == hangulV || q == hangulLV || q == hangulLVT
+	case hangulV, hangulLV:
+		return q == hangulV || q == hangulT
+	case hangulT, hangulLVT:
+		return q == hangulT
+	}
+	return false
+}
+
+// isGraphemeExtend は直前の文字と結合して1つの書記素クラスタになる文字かを返す
+// （結合文字・日本語の濁点等の結合文字・異体字セレクタ・キーキャップはMn/Me/Mc、加えて絵文字の肌色修飾子とタグ文字）
+func isGraphemeExtend(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // 絵文字の肌色修飾子
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // タグ文字（地域の旗）
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isWordRune は英単語を構成する文字（ラテン文字・数字）かを返す
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
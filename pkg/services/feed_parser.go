@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"feed-parallel-parse-api/pkg/models"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// sniffLen は形式の判定のために先読みするボディの先頭バイト数
+const sniffLen = 1024
+
+// maxReplayLen はパーサーが対象外と返した場合に次のパーサーへ渡し直せる、読み取り済みの内容の上限バイト数
+// （ボディ全体を保持しないよう、対象外の判定はボディの先頭部分で行う前提とする）
+const maxReplayLen = 64 << 10
+
+// fallbackParserPriority は形式を問わずgofeedでパースするGofeedParserの優先度（組み込みパーサーより後に試す）
+const fallbackParserPriority = -100
+
+// ErrUnsupportedFeedFormat はどのパーサーも対応していない形式であることを表す
+var ErrUnsupportedFeedFormat = errors.New("対応していないフィード形式です")
+
+// FeedSource はパース対象のフィード本体と取得元の情報
+type FeedSource struct {
+	URL      string      // リクエストしたURL（フィード内にFeedURLが無い場合に使用）
+	FinalURL string      // リダイレクト後の取得元URL
+	Header   http.Header // オリジンのレスポンスヘッダー
+	Body     io.Reader   // ParserRegistry経由の場合はUTF-8に変換済み
+}
+
+// baseURL はフィード内の相対URLを解決する基準となる文書のURL（リダイレクト後のURL、無ければリクエストしたURL）
+func (src FeedSource) baseURL() string {
+	if src.FinalURL != "" {
+		return src.FinalURL
+	}
+	return src.URL
+}
+
+// FeedParser は各フィード形式のパース共通インターフェース
+// ParserRegistryに登録すると、Detectで対象と判定された形式のフィードのパースに使われる
+type FeedParser interface {
+	// Detect はボディの先頭部分（最大sniffLenバイト）とレスポンスヘッダーから、対象の形式かを判定する
+	Detect(head []byte, header http.Header) bool
+	// Parse はフィードをパースする（対象外の形式だった場合はnil, nilを返し、次に対象と判定したパーサーを試す）
+	// 対象外の判定はボディの先頭部分（最大maxReplayLenバイト）を読み取った時点までに行う
+	Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error)
+}
+
+// ParserRegistry は優先度順にFeedParserを保持し、内容から判定した形式のパーサーにパースを振り分ける
+type ParserRegistry struct {
+	mu      sync.RWMutex
+	parsers []registeredParser
+}
+
+type registeredParser struct {
+	parser   FeedParser
+	priority int
+}
+
+// NewParserRegistry は空のParserRegistryを作成する
+func NewParserRegistry() *ParserRegistry {
+	return &ParserRegistry{}
+}
+
+// NewDefaultParserRegistry は組み込みのパーサー（JSON Feed・Atom・RSS 2.0・RSS 1.0、
+// 最後にその他のgofeed対応形式）を登録したParserRegistryを作成する
+// 組み込みパーサーの優先度は0のため、正の優先度で登録したパーサーはこれらより先に判定される
+func NewDefaultParserRegistry() *ParserRegistry {
+	r := NewParserRegistry()
+	r.Register(&JSONFeedParser{}, 0)
+	r.Register(&AtomParser{}, 0)
+	r.Register(&RSS2Parser{}, 0)
+	r.Register(&RDFParser{}, 0)
+	r.Register(&GofeedParser{}, fallbackParserPriority)
+	return r
+}
+
+// Register はパーサーを登録する（優先度が高いものから判定し、同じ優先度は登録順）
+func (r *ParserRegistry) Register(p FeedParser, priority int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parsers = append(r.parsers, registeredParser{parser: p, priority: priority})
+	sort.SliceStable(r.parsers, func(i, j int) bool {
+		return r.parsers[i].priority > r.parsers[j].priority
+	})
+}
+
+// Detect はボディの先頭部分から、最初に対象と判定したパーサーを返す（無ければnil）
+func (r *ParserRegistry) Detect(head []byte, header http.Header) FeedParser {
+	if candidates := r.candidates(head, header); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// candidates はボディの先頭部分から対象と判定したパーサーを優先度順に返す
+func (r *ParserRegistry) candidates(head []byte, header http.Header) []FeedParser {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var parsers []FeedParser
+	for _, rp := range r.parsers {
+		if rp.parser.Detect(head, header) {
+			parsers = append(parsers, rp.parser)
+		}
+	}
+	return parsers
+}
+
+// Parse はボディをUTF-8に変換し、先頭部分を先読みして形式を判定して対応するパーサーでパースする
+// パーサーが対象外と返した場合は、読み取られた内容を戻して次に対象と判定したパーサーを試す
+func (r *ParserRegistry) Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error) {
+	body, ok := src.Body.(*bufio.Reader)
+	if !ok {
+		body = bufio.NewReader(src.Body)
+	}
+	body = decodeToUTF8(body, src.Header.Get("Content-Type"))
+	head, _ := body.Peek(sniffLen)
+	var rest io.Reader = body
+	for _, parser := range r.candidates(head, src.Header) {
+		replay := &replayReader{r: rest}
+		src.Body = bufio.NewReader(replay)
+		feed, err := parser.Parse(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		if feed != nil {
+			return feed, nil
+		}
+		if replay.overflow {
+			return nil, fmt.Errorf("%w: %T がボディの先頭部分より後ろを読み取ってから対象外と返しました", ErrUnsupportedFeedFormat, parser)
+		}
+		rest = io.MultiReader(bytes.NewReader(replay.read), rest)
+	}
+	return nil, ErrUnsupportedFeedFormat
+}
+
+// replayReader は読み取った内容を最大maxReplayLenバイトまで記録するio.Reader
+// パーサーが対象外と返した場合に、記録した内容を次のパーサーへ渡し直すために使う
+type replayReader struct {
+	r        io.Reader
+	read     []byte
+	overflow bool // 上限を超えて読み取られ、記録を諦めた
+}
+
+func (r *replayReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if !r.overflow {
+		if len(r.read)+n > maxReplayLen {
+			r.overflow, r.read = true, nil
+		} else {
+			r.read = append(r.read, p[:n]...)
+		}
+	}
+	return n, err
+}
+
+// containsFold はheadに小文字のパターンが大文字・小文字を区別せずに含まれるかを返す
+func containsFold(head []byte, pattern string) bool {
+	return bytes.Contains(bytes.ToLower(head), []byte(pattern))
+}
+
+// gofeedTypes はgofeedが先頭部分から判定した形式と、パース結果のFeedTypeの対応
+var gofeedTypes = map[gofeed.FeedType]string{
+	gofeed.FeedTypeAtom: "atom",
+	gofeed.FeedTypeRSS:  "rss",
+	gofeed.FeedTypeJSON: "json",
+}
+
+// parseGofeed はgofeedでパースし、対象の形式（feedType・versionは空なら問わない）の場合のみ変換する
+func parseGofeed(src FeedSource, feedType, version string) (*models.RSSFeed, error) {
+	// 形式が異なる場合は、次のパーサーを試せるよう先頭部分だけで対象外と判定する
+	// （JSON FeedはJSONFeedParserが扱うため、形式を問わない場合もJSONは対象外とする）
+	body, ok := src.Body.(*bufio.Reader)
+	if !ok {
+		body = bufio.NewReader(src.Body)
+	}
+	head, _ := body.Peek(sniffLen)
+	detected := gofeedTypes[gofeed.DetectFeedType(bytes.NewReader(head))]
+	if (feedType != "" && detected != feedType) || (feedType == "" && detected == "json") {
+		return nil, nil // 対象外
+	}
+	feed, repairs, err := parseXMLFeed(body, src.baseURL())
+	if err != nil {
+		return nil, err
+	}
+	if (feedType != "" && feed.FeedType != feedType) || (version != "" && feed.FeedVersion != version) {
+		return nil, nil // 対象外
+	}
+	result := feedToRSSFeed(feed, src.URL, src.baseURL())
+	result.Repaired = repairs
+	return result, nil
+}
+
+// atomRootPattern はAtomのルート要素（<feed>、<atom:feed>。<feedburner:info>等は含まない）
+var atomRootPattern = regexp.MustCompile(`(?i)<([a-z0-9_-]+:)?feed[\s>]`)
+
+// AtomParserはAtom用のFeedParser実装
+type AtomParser struct{}
+
+func (p *AtomParser) Detect(head []byte, header http.Header) bool {
+	return atomRootPattern.Match(head)
+}
+
+func (p *AtomParser) Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error) {
+	return parseGofeed(src, "atom", "")
+}
+
+// rss2VersionPattern はRSS 2.0のルート要素（<rss version="2.0">）
+var rss2VersionPattern = regexp.MustCompile(`(?i)<rss\s[^>]*version\s*=\s*["']2\.0["']`)
+
+// RSS2ParserはRSS2.0用のFeedParser実装
+type RSS2Parser struct{}
+
+func (p *RSS2Parser) Detect(head []byte, header http.Header) bool {
+	return rss2VersionPattern.Match(head)
+}
+
+func (p *RSS2Parser) Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error) {
+	return parseGofeed(src, "rss", "2.0")
+}
+
+// RDFParserはRSS1.0(RDF)用のFeedParser実装
+type RDFParser struct{}
+
+func (p *RDFParser) Detect(head []byte, header http.Header) bool {
+	return containsFold(head, "<rdf:rdf")
+}
+
+func (p *RDFParser) Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error) {
+	return parseGofeed(src, "rss", "1.0")
+}
+
+// GofeedParserはgofeedが対応する全形式（RSS 0.9x等を含む）を扱う汎用のFeedParser実装
+// どの形式とも判定されなかったフィードを最後に試すために使う
+type GofeedParser struct{}
+
+func (p *GofeedParser) Detect(head []byte, header http.Header) bool {
+	return true
+}
+
+func (p *GofeedParser) Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error) {
+	return parseGofeed(src, "", "")
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"feed-parallel-parse-api/pkg/models"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type fetchTraceKey struct{}
+
+// fetchTrace は1回の取得で発生したHTTP通信の計測値を集める
+// httptraceのコールバックはダイヤル用のゴルーチン等からも呼ばれるため、mutexで保護する
+type fetchTrace struct {
+	mu sync.Mutex
+
+	start                         time.Time
+	hopStart                      time.Time
+	dnsStart, connectStart, tlsAt time.Time
+	dns, connect, tls, ttfb       time.Duration
+
+	finalURL  string
+	redirects []models.Redirect
+	resp      *http.Response
+
+	wire *countingReader // 転送されたボディ（圧縮時のみ）
+	body *limitedBody    // 展開後のボディ
+}
+
+// withFetchTrace は計測を開始し、httptraceを設定したコンテキストを返す
+func withFetchTrace(ctx context.Context, u string) (context.Context, *fetchTrace) {
+	t := &fetchTrace{start: time.Now(), finalURL: u}
+	ctx = context.WithValue(ctx, fetchTraceKey{}, t)
+	return httptrace.WithClientTrace(ctx, t.clientTrace()), t
+}
+
+// fetchTraceFrom はコンテキストに設定された計測を返す（無ければnil）
+func fetchTraceFrom(ctx context.Context) *fetchTrace {
+	t, _ := ctx.Value(fetchTraceKey{}).(*fetchTrace)
+	return t
+}
+
+func (t *fetchTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			t.hopStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.dns += elapsedSince(&t.dnsStart)
+			t.mu.Unlock()
+		},
+		// 複数アドレスへ並行して接続する場合は、最初の開始から成功までを接続時間とする
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err != nil {
+				return
+			}
+			t.mu.Lock()
+			t.connect += elapsedSince(&t.connectStart)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsAt = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.tls += elapsedSince(&t.tlsAt)
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			if !t.hopStart.IsZero() {
+				t.ttfb = time.Since(t.hopStart)
+			}
+			t.mu.Unlock()
+		},
+	}
+}
+
+// redirect はリダイレクトを記録する（CheckRedirectから呼ばれる）
+func (t *fetchTrace) redirect(req *http.Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if req.Response != nil {
+		t.redirects = append(t.redirects, models.Redirect{
+			URL:        req.Response.Request.URL.String(),
+			StatusCode: req.Response.StatusCode,
+		})
+	}
+	t.finalURL = req.URL.String()
+}
+
+// finish は計測を終了してFetchInfoを組み立てる
+func (t *fetchTrace) finish() *models.FetchInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info := &models.FetchInfo{
+		FinalURL:  t.finalURL,
+		Redirects: t.redirects,
+		Timings: models.FetchTimings{
+			DNS:     milliseconds(t.dns),
+			Connect: milliseconds(t.connect),
+			TLS:     milliseconds(t.tls),
+			TTFB:    milliseconds(t.ttfb),
+			Total:   milliseconds(time.Since(t.start)),
+		},
+	}
+	if t.resp != nil {
+		info.StatusCode = t.resp.StatusCode
+		info.ContentType = t.resp.Header.Get("Content-Type")
+		info.FinalURL = t.resp.Request.URL.String()
+	}
+	if t.body != nil {
+		info.Bytes = t.body.limit - t.body.remaining
+	}
+	if t.wire != nil {
+		info.CompressedBytes = t.wire.n
+	}
+	return info
+}
+
+// permanentRedirectTarget はすべてのリダイレクトが恒久的（301/308）だった場合に最終URLを返す
+// 一時的なリダイレクトを1つでも経由した場合は、元のURLを使い続けるべきなので空文字を返す
+func permanentRedirectTarget(info *models.FetchInfo) string {
+	if info == nil || len(info.Redirects) == 0 {
+		return ""
+	}
+	for _, r := range info.Redirects {
+		if r.StatusCode != http.StatusMovedPermanently && r.StatusCode != http.StatusPermanentRedirect {
+			return ""
+		}
+	}
+	return info.FinalURL
+}
+
+// elapsedSince は開始時刻からの経過時間を返し、開始時刻をリセットする
+func elapsedSince(start *time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	d := time.Since(*start)
+	*start = time.Time{}
+	return d
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// countingReader は読み取ったバイト数を数えるReader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
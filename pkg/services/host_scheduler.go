@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHostConcurrency は同一ホストへの同時接続数のデフォルト上限
+	DefaultHostConcurrency = 4
+	// DefaultHostMinInterval は同一ホストへのリクエスト開始間隔のデフォルトの最小値（オリジンへの配慮）
+	DefaultHostMinInterval = 100 * time.Millisecond
+	// DefaultHostMaxWait はレート制限中のホストに対して待機する時間のデフォルト上限
+	DefaultHostMaxWait = 3 * time.Second
+	// defaultRateLimitBackoff はRetry-Afterが無い429応答を受けた際の待機時間
+	defaultRateLimitBackoff = 5 * time.Second
+	// maxRateLimitBackoff はRetry-Afterとして受け入れる待機時間の上限
+	maxRateLimitBackoff = 10 * time.Minute
+)
+
+// rateLimitedError はホストがレート制限中で待機上限を超えるため取得を見送ったことを表す
+type rateLimitedError struct {
+	host       string
+	retryAfter time.Duration
+}
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("ホスト %s はレート制限中です（%d秒後に再試行可能）", e.host, retryAfterSeconds(e.retryAfter))
+}
+
+// hostScheduler はホスト単位で同時接続数とリクエスト間隔を制御する
+type hostScheduler struct {
+	maxPerHost  int
+	minInterval time.Duration
+	maxWait     time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState は1ホスト分のスケジューリング状態
+type hostState struct {
+	slots        chan struct{}
+	nextStart    time.Time // 最小間隔を考慮した次回リクエスト開始可能時刻
+	blockedUntil time.Time // 429/Retry-Afterにより待機が必要な時刻
+}
+
+func newHostScheduler(maxPerHost int, minInterval, maxWait time.Duration) *hostScheduler {
+	return &hostScheduler{
+		maxPerHost:  maxPerHost,
+		minInterval: minInterval,
+		maxWait:     maxWait,
+		hosts:       make(map[string]*hostState),
+	}
+}
+
+func (h *hostScheduler) state(host string) *hostState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st, ok := h.hosts[host]
+	if !ok {
+		st = &hostState{slots: make(chan struct{}, h.maxPerHost)}
+		h.hosts[host] = st
+	}
+	return st
+}
+
+// acquire はホストへの接続枠を確保し、最小間隔・Retry-Afterを満たすまで待機する
+// 戻り値のrelease関数はリクエスト完了後に必ず呼び出すこと
+func (h *hostScheduler) acquire(ctx context.Context, host string) (func(), error) {
+	st := h.state(host)
+	select {
+	case st.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { h.release(host, st) }
+
+	h.mu.Lock()
+	now := time.Now()
+	start := now
+	if st.nextStart.After(start) {
+		start = st.nextStart
+	}
+	if st.blockedUntil.After(start) {
+		start = st.blockedUntil
+		if wait := start.Sub(now); wait > h.maxWait {
+			h.mu.Unlock()
+			release()
+			return nil, &rateLimitedError{host: host, retryAfter: wait}
+		}
+	}
+	st.nextStart = start.Add(h.minInterval)
+	h.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// release は接続枠を返却し、待機状態の残っていないホストの状態を破棄する
+func (h *hostScheduler) release(host string, st *hostState) {
+	<-st.slots
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if len(st.slots) == 0 && !st.nextStart.After(now) && !st.blockedUntil.After(now) && h.hosts[host] == st {
+		delete(h.hosts, host)
+	}
+}
+
+// penalize はレート制限応答を受けたホストへのリクエストをretryAfterの間停止する
+func (h *hostScheduler) penalize(host string, retryAfter time.Duration) {
+	if retryAfter > maxRateLimitBackoff {
+		retryAfter = maxRateLimitBackoff
+	}
+	st := h.state(host)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until := time.Now().Add(retryAfter); until.After(st.blockedUntil) {
+		st.blockedUntil = until
+	}
+}
+
+// parseRetryAfter はRetry-Afterヘッダー（秒数またはHTTP日付）を待機時間に変換する
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// retryAfterSeconds は待機時間を切り上げた秒数に変換する
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"feed-parallel-parse-api/pkg/models"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// jsonFeedVersionPrefix はJSON Feedのversionの接頭辞（1.0/1.1共通）
+const jsonFeedVersionPrefix = "://jsonfeed.org/version/"
+
+// errNotJSONFeed はJSONとしては正しいがJSON Feedではないことを表す
+var errNotJSONFeed = errors.New("JSON Feedではありません（versionが不正です）")
+
+// JSONFeedParserはJSON Feed（https://jsonfeed.org/ 1.0/1.1）用のFeedParser実装
+type JSONFeedParser struct{}
+
+// Detect は先頭の空白・BOMを除いた最初の文字からボディがJSONかを判定する
+func (p *JSONFeedParser) Detect(head []byte, header http.Header) bool {
+	return isJSONObject(head)
+}
+
+// isJSONObject は先頭の空白・BOMを除いた最初の文字が"{"か（JSONのオブジェクトか）を返す
+func isJSONObject(head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	head = bytes.TrimLeft(head, " \t\r\n")
+	return len(head) > 0 && head[0] == '{'
+}
+
+func (p *JSONFeedParser) Parse(ctx context.Context, src FeedSource) (*models.RSSFeed, error) {
+	feed, err := parseJSONFeed(src.Body, src.baseURL())
+	if errors.Is(err, errNotJSONFeed) {
+		return nil, nil // 対象外
+	}
+	if err != nil {
+		return nil, err
+	}
+	if feed.FeedURL == "" {
+		feed.FeedURL = src.URL
+	}
+	return feed, nil
+}
+
+// jsonFeed はJSON Feedのトップレベルオブジェクト
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Favicon     string           `json:"favicon"`
+	Language    string           `json:"language"` // 1.1で追加
+	Author      *jsonFeedAuthor  `json:"author"`   // 1.0（1.1では非推奨）
+	Authors     []jsonFeedAuthor `json:"authors"`  // 1.1で追加
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            jsonFeedID           `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	Image         string               `json:"image"`
+	BannerImage   string               `json:"banner_image"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *jsonFeedAuthor      `json:"author"`
+	Authors       []jsonFeedAuthor     `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+// jsonFeedID はitemのid（仕様上は文字列だが、数値で出力するフィードもあるため両方を受け付ける）
+type jsonFeedID string
+
+func (id *jsonFeedID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = jsonFeedID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = jsonFeedID(n.String())
+	return nil
+}
+
+type jsonFeedAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Avatar string `json:"avatar"`
+}
+
+type jsonFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	Title             string  `json:"title"`
+	SizeInBytes       float64 `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+// parseJSONFeed はJSON Feedをストリームで読み取りmodels.RSSFeedに変換する
+// FeedURLはfeed_urlが無い場合は空のままとなる（呼び出し元でリクエストURLを設定する）
+// 相対URLはdocURL（リダイレクト後の取得元URL）を基準に解決し、記事のURLはhome_page_url → feed_url → docURLを基準とする
+func parseJSONFeed(r io.Reader, docURL string) (*models.RSSFeed, error) {
+	var doc jsonFeed
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(doc.Version, jsonFeedVersionPrefix) {
+		return nil, errNotJSONFeed
+	}
+
+	feedAuthors := jsonFeedAuthors(doc.Authors, doc.Author)
+	link := resolveURL(docURL, doc.HomePageURL)
+	feedURL := resolveURL(docURL, doc.FeedURL)
+	siteURL := link
+	if siteURL == "" {
+		siteURL = feedURL
+	}
+	if siteURL == "" {
+		siteURL = docURL
+	}
+	articles := make([]models.Article, 0, len(doc.Items))
+	for _, item := range doc.Items {
+		articles = append(articles, jsonFeedItemToArticle(item, feedAuthors, siteURL))
+	}
+
+	image := doc.Icon
+	if image == "" {
+		image = doc.Favicon
+	}
+	image = resolveURL(siteURL, image)
+	return &models.RSSFeed{
+		Title:       doc.Title,
+		Link:        link,
+		FeedURL:     feedURL,
+		Articles:    articles,
+		Description: doc.Description,
+		Language:    doc.Language,
+		Image:       image,
+		Authors:     feedAuthors,
+	}, nil
+}
+
+func jsonFeedItemToArticle(item jsonFeedItem, feedAuthors []models.Author, siteURL string) models.Article {
+	// Link: url → external_url（リンク記事の場合はurlが無くexternal_urlのみのことがある）
+	link := item.URL
+	if link == "" {
+		link = item.ExternalURL
+	}
+	link = resolveURL(siteURL, link)
+	// Summary: summary → content_html → content_text（既存クライアントはSummaryを表示するため本文で補う）
+	summary := item.Summary
+	if summary == "" {
+		summary = item.ContentHTML
+	}
+	if summary == "" {
+		summary = item.ContentText
+	}
+	id := string(item.ID)
+	if id == "" {
+		id = link
+	}
+	// 記事に著者が無い場合はフィードの著者を引き継ぐ（JSON Feedの仕様）
+	authors := jsonFeedAuthors(item.Authors, item.Author)
+	if authors == nil {
+		authors = feedAuthors
+	}
+
+	// サムネイル: image → banner_image → content_htmlの最初の<img>（本文中の相対URLは記事のURLを基準とする）
+	image := resolveURL(siteURL, item.Image)
+	bannerImage := resolveURL(siteURL, item.BannerImage)
+	thumb := thumbnail{url: image}
+	if thumb.url == "" {
+		thumb.url = bannerImage
+	}
+	if thumb.url == "" {
+		thumb = htmlThumbnail(item.ContentHTML)
+	}
+
+	var attachments []models.Attachment
+	for _, a := range item.Attachments {
+		if a.URL == "" {
+			continue
+		}
+		attachments = append(attachments, models.Attachment{
+			URL:               resolveURL(siteURL, a.URL),
+			MimeType:          a.MimeType,
+			Title:             a.Title,
+			SizeInBytes:       int64(a.SizeInBytes),
+			DurationInSeconds: a.DurationInSeconds,
+		})
+	}
+
+	return models.Article{
+		Title:       item.Title,
+		Link:        link,
+		PubDate:     item.DatePublished,
+		Summary:     summary,
+		ID:          id,
+		Updated:     item.DateModified,
+		PublishedAt: normalizeDate(item.DatePublished, nil),
+		UpdatedAt:   normalizeDate(item.DateModified, nil),
+		ContentHTML: item.ContentHTML,
+		ContentText: item.ContentText,
+		Image:       image,
+		BannerImage: bannerImage,
+		ImageURL:    thumb.resolve(siteURL, link),
+		ImageWidth:  thumb.width,
+		ImageHeight: thumb.height,
+		Authors:     authors,
+		Categories:  item.Tags,
+		Attachments: attachments,
+	}
+}
+
+// jsonFeedAuthors は1.1のauthors、無ければ1.0のauthorを著者一覧に変換する
+func jsonFeedAuthors(authors []jsonFeedAuthor, author *jsonFeedAuthor) []models.Author {
+	if len(authors) == 0 && author != nil {
+		authors = []jsonFeedAuthor{*author}
+	}
+	var result []models.Author
+	for _, a := range authors {
+		if a.Name == "" && a.URL == "" && a.Avatar == "" {
+			continue
+		}
+		result = append(result, models.Author{Name: a.Name, URL: a.URL, Avatar: a.Avatar})
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"feed-parallel-parse-api/pkg/models"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// podcastNamespacePrefix はPodcasting 2.0（https://podcastindex.org/namespace/1.0）の名前空間接頭辞
+// gofeedは未知の名前空間をフィード内で宣言された接頭辞で保持するため、慣例の"podcast"で参照する
+const podcastNamespacePrefix = "podcast"
+
+// enclosureAttachments はRSSのenclosure・Atomのrel="enclosure"リンクを添付ファイルに変換する
+// iTunesの再生時間は最初の音声・動画の添付ファイルに設定する
+func enclosureAttachments(item *gofeed.Item, duration float64) []models.Attachment {
+	var attachments []models.Attachment
+	for _, enc := range item.Enclosures {
+		if enc == nil || enc.URL == "" {
+			continue
+		}
+		size, _ := strconv.ParseInt(strings.TrimSpace(enc.Length), 10, 64)
+		attachment := models.Attachment{
+			URL:         enc.URL,
+			MimeType:    enc.Type,
+			SizeInBytes: max(size, 0),
+		}
+		if duration > 0 && isPlayableMedia(enc.Type) {
+			attachment.DurationInSeconds = duration
+			duration = 0
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
+
+// isPlayableMedia は音声・動画のMIMEタイプかを返す
+func isPlayableMedia(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "audio/") || strings.HasPrefix(mimeType, "video/")
+}
+
+// podcastEpisode は記事のiTunes・Podcasting 2.0拡張をエピソード情報に変換する（どちらも無い場合はnil）
+func podcastEpisode(item *gofeed.Item) *models.PodcastEpisode {
+	podcast := item.Extensions[podcastNamespacePrefix]
+	if item.ITunesExt == nil && len(podcast) == 0 {
+		return nil
+	}
+	episode := &models.PodcastEpisode{}
+	if it := item.ITunesExt; it != nil {
+		episode.DurationInSeconds = parseDuration(it.Duration)
+		episode.Episode, _ = strconv.Atoi(strings.TrimSpace(it.Episode))
+		episode.Season, _ = strconv.Atoi(strings.TrimSpace(it.Season))
+		episode.EpisodeType = itunesEnum(it.EpisodeType, "full", "trailer", "bonus")
+		episode.Explicit = parseExplicit(it.Explicit)
+		episode.Image = it.Image
+	}
+	for _, e := range podcast["transcript"] {
+		if e.Attrs["url"] == "" {
+			continue
+		}
+		episode.Transcripts = append(episode.Transcripts, models.PodcastTranscript{
+			URL:      e.Attrs["url"],
+			Type:     e.Attrs["type"],
+			Language: e.Attrs["language"],
+			Rel:      e.Attrs["rel"],
+		})
+	}
+	for _, e := range podcast["chapters"] {
+		if e.Attrs["url"] != "" {
+			episode.Chapters = &models.PodcastChapters{URL: e.Attrs["url"], Type: e.Attrs["type"]}
+			break
+		}
+	}
+	episode.Funding = podcastFunding(podcast["funding"])
+	return episode
+}
+
+// feedPodcast はフィードのiTunes・Podcasting 2.0拡張をポッドキャスト情報に変換する（どちらも無い場合はnil）
+func feedPodcast(feed *gofeed.Feed) *models.Podcast {
+	podcast := feed.Extensions[podcastNamespacePrefix]
+	if feed.ITunesExt == nil && len(podcast) == 0 {
+		return nil
+	}
+	result := &models.Podcast{}
+	if it := feed.ITunesExt; it != nil {
+		result.Author = it.Author
+		if it.Owner != nil && (it.Owner.Name != "" || it.Owner.Email != "") {
+			result.Owner = &models.Author{Name: it.Owner.Name, Email: it.Owner.Email}
+		}
+		result.Categories = itunesCategories(it.Categories)
+		result.Explicit = parseExplicit(it.Explicit)
+		result.Image = it.Image
+		result.Type = itunesEnum(it.Type, "episodic", "serial")
+		result.Complete = strings.EqualFold(strings.TrimSpace(it.Complete), "yes")
+		result.NewFeedURL = strings.TrimSpace(it.NewFeedURL)
+	}
+	if guid := podcast["guid"]; len(guid) > 0 {
+		result.GUID = strings.TrimSpace(guid[0].Value)
+	}
+	result.Funding = podcastFunding(podcast["funding"])
+	return result
+}
+
+// podcastFunding はpodcast:fundingを支援の案内に変換する
+func podcastFunding(elements []ext.Extension) []models.PodcastFunding {
+	var funding []models.PodcastFunding
+	for _, e := range elements {
+		if e.Attrs["url"] == "" {
+			continue
+		}
+		funding = append(funding, models.PodcastFunding{URL: e.Attrs["url"], Title: strings.TrimSpace(e.Value)})
+	}
+	return funding
+}
+
+// itunesCategories はiTunesのカテゴリーを"親 > 子"形式の文字列にする
+func itunesCategories(categories []*ext.ITunesCategory) []string {
+	var result []string
+	for _, c := range categories {
+		if c == nil || c.Text == "" {
+			continue
+		}
+		result = append(result, c.Text)
+		if c.Subcategory != nil && c.Subcategory.Text != "" {
+			result = append(result, c.Text+" > "+c.Subcategory.Text)
+		}
+	}
+	return result
+}
+
+// parseExplicit はitunes:explicitを解釈する（yes/true/explicitは真、no/false/cleanは偽、それ以外はnil）
+func parseExplicit(value string) *bool {
+	var explicit bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "explicit":
+		explicit = true
+	case "no", "false", "clean":
+		explicit = false
+	default:
+		return nil
+	}
+	return &explicit
+}
+
+// itunesEnum はiTunes拡張の列挙値を小文字にして返す（allowed以外の値は空）
+func itunesEnum(value string, allowed ...string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if slices.Contains(allowed, value) {
+		return value
+	}
+	return ""
+}
+
+// parseDuration はitunes:durationを秒数に変換する（"HH:MM:SS"、"MM:SS"、秒数のいずれか。解釈できない場合は0）
+func parseDuration(value string) float64 {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	var seconds float64
+	for _, p := range parts {
+		n, err := strconv.ParseFloat(p, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
+
+// resolvePodcastURLs はフィードのポッドキャスト情報に含まれる相対URLをbaseを基準に絶対URLにする
+func resolvePodcastURLs(p *models.Podcast, base string) {
+	p.Image = resolveURL(base, p.Image)
+	p.NewFeedURL = resolveURL(base, p.NewFeedURL)
+	resolveFundingURLs(p.Funding, base)
+}
+
+// resolvePodcastEpisodeURLs はエピソードのポッドキャスト情報に含まれる相対URLをbaseを基準に絶対URLにする
+func resolvePodcastEpisodeURLs(p *models.PodcastEpisode, base string) {
+	p.Image = resolveURL(base, p.Image)
+	for i := range p.Transcripts {
+		p.Transcripts[i].URL = resolveURL(base, p.Transcripts[i].URL)
+	}
+	if p.Chapters != nil {
+		p.Chapters.URL = resolveURL(base, p.Chapters.URL)
+	}
+	resolveFundingURLs(p.Funding, base)
+}
+
+func resolveFundingURLs(funding []models.PodcastFunding, base string) {
+	for i := range funding {
+		funding[i].URL = resolveURL(base, funding[i].URL)
+	}
+}
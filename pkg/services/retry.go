@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+)
+
+// RetryPolicy は一時的な取得失敗に対する再試行の方針
+type RetryPolicy struct {
+	MaxAttempts       int           // 初回を含む最大試行回数（1以下は再試行しない）
+	BaseDelay         time.Duration // 1回目の再試行までの待機時間（以降は2倍ずつ増加）
+	MaxDelay          time.Duration // 待機時間の上限（これを超えるRetry-Afterが指定された場合は再試行しない）
+	Jitter            float64       // 待機時間をランダムに短縮する割合（0〜1）
+	RetryableStatuses []int         // 再試行対象のHTTPステータスコード
+}
+
+// DefaultRetryPolicy は公開APIで使用する再試行方針
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       3,
+	BaseDelay:         200 * time.Millisecond,
+	MaxDelay:          2 * time.Second,
+	Jitter:            0.5,
+	RetryableStatuses: []int{408, 425, 429, 500, 502, 503, 504},
+}
+
+// noRetryPolicy はRSSServiceのデフォルト（再試行しない）
+var noRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// retryableStatus はステータスコードが再試行対象か判定する
+func (p RetryPolicy) retryableStatus(code int) bool {
+	for _, c := range p.RetryableStatuses {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff はattempt回目の試行が失敗した後の待機時間（指数バックオフ＋ジッター）を返す
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// fetchFeed は再試行方針に従ってフィードを取得し、試行回数を結果に記録する
+// 待機時間がRetry-Afterの上限やコンテキストの期限に収まらない場合は再試行せずに最後の結果を返す
+func (s *RSSService) fetchFeed(ctx context.Context, u string, validator models.FeedValidator) feedResult {
+	policy := s.retryPolicy
+	var result feedResult
+	attempt := 1
+	for ; ; attempt++ {
+		result = s.fetchOnce(ctx, u, validator, false)
+		if !result.retryable || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+		if result.retryAfter > policy.MaxDelay {
+			break
+		}
+		delay := policy.backoff(attempt)
+		if result.retryAfter > delay {
+			delay = result.retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			break
+		}
+		if !sleepContext(ctx, delay) {
+			break
+		}
+	}
+	return result.withAttempts(attempt)
+}
+
+// withAttempts は試行回数と計測情報をフィード・エラーに記録した結果を返す
+func (r feedResult) withAttempts(attempt int) feedResult {
+	r.attempts = attempt
+	switch {
+	case r.feed != nil:
+		r.feed.Attempts = attempt
+		r.feed.FetchInfo = r.fetchInfo
+		r.feed.MovedTo = r.movedTo
+	case r.err != nil:
+		r.err.Attempts = attempt
+		r.err.FetchInfo = r.fetchInfo
+	}
+	return r
+}
+
+// sleepContext はdの間待機する。コンテキストが先に終了した場合はfalseを返す
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isRetryableFetchError はHTTPクライアントのエラーが一時的なもの（タイムアウト・接続断など）か判定する
+func isRetryableFetchError(err error) bool {
+	var blocked *blockedAddressError
+	if errors.As(err, &blocked) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
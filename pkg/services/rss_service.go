@@ -1,232 +1,627 @@
 package services
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
 	"errors"
 	"feed-parallel-parse-api/pkg/models"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/mmcdole/gofeed"
 )
 
 // feedToRSSFeedはgofeed.Feedをmodels.RSSFeedに変換する共通処理
-func feedToRSSFeed(feed *gofeed.Feed, requestedURL string) *models.RSSFeed {
+func feedToRSSFeed(feed *gofeed.Feed, requestedURL, docURL string) *models.RSSFeed {
+	// 相対URLの解決（xml:baseの範囲内はxmlBaseResolverで解決済み）: フィードのリンクは取得元URL、記事のURL・画像等はフィードのリンク（無ければ取得元URL）を基準とする
+	link := resolveURL(docURL, feed.Link)
+	siteURL := link
+	if siteURL == "" {
+		siteURL = docURL
+	}
+
 	// FeedURLの設定（優先順位: feed.FeedLink → requestedURL）
-	feedURL := feed.FeedLink
+	feedURL := resolveURL(docURL, feed.FeedLink)
 	if feedURL == "" {
 		feedURL = requestedURL
 	}
 
+	feedAuthors := gofeedAuthors(feed.Authors)
 	articles := make([]models.Article, 0, len(feed.Items))
 	for _, item := range feed.Items {
+		itemLink := resolveURL(siteURL, item.Link)
+		id := item.GUID
+		if id == "" {
+			id = itemLink
+		}
+		// Atomではentryに著者が無い場合はfeedの著者を引き継ぐ（RFC 4287 4.2.1）
+		authors := gofeedAuthors(item.Authors)
+		if authors == nil && feed.FeedType == "atom" {
+			authors = feedAuthors
+		}
+		var image string
+		if item.Image != nil {
+			image = resolveURL(siteURL, item.Image.URL)
+		}
+		thumb := itemThumbnail(item)
+		podcast := podcastEpisode(item)
+		var duration float64
+		if podcast != nil {
+			duration = podcast.DurationInSeconds
+			resolvePodcastEpisodeURLs(podcast, siteURL)
+		}
+		attachments := enclosureAttachments(item, duration)
+		for i := range attachments {
+			attachments[i].URL = resolveURL(siteURL, attachments[i].URL)
+		}
 		articles = append(articles, models.Article{
-			Title:   item.Title,
-			Link:    item.Link,
-			PubDate: item.Published,
-			Summary: item.Description,
+			Title:       item.Title,
+			Link:        itemLink,
+			PubDate:     item.Published,
+			Summary:     item.Description,
+			ID:          id,
+			Updated:     item.Updated,
+			PublishedAt: normalizeDate(item.Published, item.PublishedParsed),
+			UpdatedAt:   normalizeDate(item.Updated, item.UpdatedParsed),
+			ContentHTML: item.Content, // content:encoded（RSS）、content（Atom）
+			Image:       image,
+			ImageURL:    thumb.resolve(siteURL, itemLink),
+			ImageWidth:  thumb.width,
+			ImageHeight: thumb.height,
+			Authors:     authors,
+			Categories:  item.Categories,
+			Attachments: attachments,
+			Podcast:     podcast,
 		})
 	}
+	var image string
+	if feed.Image != nil {
+		image = resolveURL(siteURL, feed.Image.URL)
+	}
+	podcast := feedPodcast(feed)
+	if podcast != nil {
+		resolvePodcastURLs(podcast, siteURL)
+	}
 	return &models.RSSFeed{
-		Title:    feed.Title,
-		Link:     feed.Link,
-		FeedURL:  feedURL, // 追加
-		Articles: articles,
+		Title:       feed.Title,
+		Link:        link,
+		FeedURL:     feedURL, // 追加
+		Articles:    articles,
+		Description: feed.Description,
+		Language:    feed.Language,
+		Image:       image,
+		Authors:     feedAuthors,
+		Podcast:     podcast,
+	}
+}
+
+// gofeedAuthorsはgofeedの著者一覧をmodels.Authorに変換する
+func gofeedAuthors(persons []*gofeed.Person) []models.Author {
+	var authors []models.Author
+	for _, p := range persons {
+		if p == nil || (p.Name == "" && p.Email == "") {
+			continue
+		}
+		authors = append(authors, models.Author{Name: p.Name, Email: p.Email})
 	}
+	return authors
 }
 
-// AtomParserはAtom用のFeedParser実装
-type AtomParser struct{}
+// DefaultMaxConcurrency はフィード取得の同時実行数のデフォルト上限
+const DefaultMaxConcurrency = 10
 
-func (p *AtomParser) Parse(ctx context.Context, data []byte) (*models.RSSFeed, error) {
-	parser := gofeed.NewParser()
-	feed, err := parser.ParseString(string(data))
-	if err != nil {
-		return nil, err
+// RSSService provides methods to fetch and parse RSS feeds
+type RSSService struct {
+	httpClient     *http.Client
+	maxConcurrency int
+
+	hostConcurrency int
+	hostMinInterval time.Duration
+	hostMaxWait     time.Duration
+	hosts           *hostScheduler
+
+	cache       FeedCache
+	cachePolicy cachePolicy
+	refreshing  sync.Map // バックグラウンド再検証中のキャッシュキー
+
+	flights flightGroup // 同一URLの同時取得をまとめる
+
+	allowlist []string // SSRF対策の対象外とする接続先
+
+	maxFeedSize int64
+	retryPolicy RetryPolicy
+
+	breakerThreshold   int
+	breakerOpenTimeout time.Duration
+	breaker            *circuitBreaker
+
+	parsers *ParserRegistry // フィード形式ごとのパーサー
+}
+
+// RSSServiceOption はRSSServiceの設定を変更する関数オプション
+type RSSServiceOption func(*RSSService)
+
+// WithMaxConcurrency は同時に取得するフィード数の上限を設定する（1未満は無視）
+func WithMaxConcurrency(n int) RSSServiceOption {
+	return func(s *RSSService) {
+		if n > 0 {
+			s.maxConcurrency = n
+		}
 	}
-	// Atomのみを対象
-	if feed.FeedType != "atom" {
-		return nil, nil // 対象外
+}
+
+// WithHostConcurrency は同一ホストへの同時接続数の上限を設定する（1未満は無視）
+func WithHostConcurrency(n int) RSSServiceOption {
+	return func(s *RSSService) {
+		if n > 0 {
+			s.hostConcurrency = n
+		}
 	}
-	// Note: このParser interfaceではrequestURLが渡されないため、空文字列を渡す
-	// 実際のFeedURL設定はParseFeedsで行われる
-	return feedToRSSFeed(feed, ""), nil
 }
 
-// RSS2ParserはRSS2.0用のFeedParser実装
-type RSS2Parser struct{}
+// WithHostMinInterval は同一ホストへのリクエスト開始間隔の最小値を設定する
+func WithHostMinInterval(d time.Duration) RSSServiceOption {
+	return func(s *RSSService) {
+		if d >= 0 {
+			s.hostMinInterval = d
+		}
+	}
+}
 
-func (p *RSS2Parser) Parse(ctx context.Context, data []byte) (*models.RSSFeed, error) {
-	parser := gofeed.NewParser()
-	feed, err := parser.ParseString(string(data))
-	if err != nil {
-		return nil, err
+// WithHostMaxWait はRetry-Afterで待機中のホストに対して待つ時間の上限を設定する
+// これを超える待機が必要な場合はリクエストせずにエラーを返す
+func WithHostMaxWait(d time.Duration) RSSServiceOption {
+	return func(s *RSSService) {
+		if d >= 0 {
+			s.hostMaxWait = d
+		}
 	}
-	// RSS2.0のみを対象
-	if feed.FeedType != "rss" || feed.FeedVersion != "2.0" {
-		return nil, nil // 対象外
+}
+
+// WithAllowedHosts はSSRF対策の対象外とする接続先（ホスト名・IPアドレス・CIDR表記）を設定する
+// デフォルトではループバック・リンクローカル・プライベート等の内部アドレスへの接続を拒否するため、
+// ローカル開発でlocalhostなどのフィードを取得する場合に使用する
+func WithAllowedHosts(entries ...string) RSSServiceOption {
+	return func(s *RSSService) {
+		s.allowlist = append(s.allowlist, entries...)
 	}
-	// Note: このParser interfaceではrequestURLが渡されないため、空文字列を渡す
-	return feedToRSSFeed(feed, ""), nil
 }
 
-// RDFParserはRSS1.0(RDF)用のFeedParser実装
-type RDFParser struct{}
+// WithMaxFeedSize はフィード本体（展開後）の最大バイト数を設定する（1未満は無視）
+func WithMaxFeedSize(n int64) RSSServiceOption {
+	return func(s *RSSService) {
+		if n > 0 {
+			s.maxFeedSize = n
+		}
+	}
+}
 
-func (p *RDFParser) Parse(ctx context.Context, data []byte) (*models.RSSFeed, error) {
-	parser := gofeed.NewParser()
-	feed, err := parser.ParseString(string(data))
-	if err != nil {
-		return nil, err
+// WithRetryPolicy は一時的な取得失敗（タイムアウト・接続断・502/503等）の再試行方針を設定する
+// 未設定の場合は再試行しない
+func WithRetryPolicy(p RetryPolicy) RSSServiceOption {
+	return func(s *RSSService) {
+		if p.MaxAttempts < 1 {
+			p.MaxAttempts = 1
+		}
+		s.retryPolicy = p
 	}
-	// RSS1.0(RDF)のみを対象にする場合はType判定も可能
-	if feed.FeedType != "rss" || feed.FeedVersion != "1.0" {
-		return nil, nil // 対象外
+}
+
+// WithCircuitBreaker はホスト単位のサーキットブレーカーを設定する
+// 連続してthreshold回失敗したホストへのリクエストをopenTimeoutの間停止し、その後1件だけ試して復旧を確認する
+func WithCircuitBreaker(threshold int, openTimeout time.Duration) RSSServiceOption {
+	return func(s *RSSService) {
+		if threshold > 0 && openTimeout > 0 {
+			s.breakerThreshold = threshold
+			s.breakerOpenTimeout = openTimeout
+		}
 	}
-	// Note: このParser interfaceではrequestURLが渡されないため、空文字列を渡す
-	return feedToRSSFeed(feed, ""), nil
 }
 
-// FeedParser は各RSS/Atom形式のパース共通インターフェース
-type FeedParser interface {
-	Parse(ctx context.Context, data []byte) (*models.RSSFeed, error)
+// WithCache はフィード取得結果のキャッシュを設定する（未設定の場合はキャッシュしない）
+func WithCache(c FeedCache) RSSServiceOption {
+	return func(s *RSSService) {
+		s.cache = c
+	}
 }
 
-// RSSService provides methods to fetch and parse RSS feeds
-type RSSService struct {
-	httpClient *http.Client
-}
-
-func NewRSSService() *RSSService {
-	return &RSSService{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return errors.New("リダイレクトが10回を超えました")
-				}
-				return nil
-			},
+// WithCacheTTL はオリジンのCache-Control/Expiresから求めた鮮度期間を丸める下限・上限を設定する
+func WithCacheTTL(min, max time.Duration) RSSServiceOption {
+	return func(s *RSSService) {
+		if min >= 0 && max >= min {
+			s.cachePolicy.minTTL = min
+			s.cachePolicy.maxTTL = max
+		}
+	}
+}
+
+// WithStaleWhileRevalidate はオリジンが指定しない場合のstale-while-revalidate期間を設定する
+// この期間内の期限切れキャッシュは即座に返され、バックグラウンドで再取得される
+func WithStaleWhileRevalidate(d time.Duration) RSSServiceOption {
+	return func(s *RSSService) {
+		if d >= 0 {
+			s.cachePolicy.swr = d
+		}
+	}
+}
+
+// WithParserRegistry はフィードのパースに使うParserRegistryを置き換える
+func WithParserRegistry(r *ParserRegistry) RSSServiceOption {
+	return func(s *RSSService) {
+		if r != nil {
+			s.parsers = r
+		}
+	}
+}
+
+// WithFeedParser は独自形式のFeedParserを登録する
+// 優先度が正の場合は組み込みのパーサーより先に判定される
+func WithFeedParser(p FeedParser, priority int) RSSServiceOption {
+	return func(s *RSSService) {
+		s.parsers.Register(p, priority)
+	}
+}
+
+func NewRSSService(opts ...RSSServiceOption) *RSSService {
+	s := &RSSService{
+		maxConcurrency:  DefaultMaxConcurrency,
+		hostConcurrency: DefaultHostConcurrency,
+		hostMinInterval: DefaultHostMinInterval,
+		hostMaxWait:     DefaultHostMaxWait,
+		cachePolicy:     cachePolicy{minTTL: DefaultCacheMinTTL, maxTTL: DefaultCacheMaxTTL},
+		maxFeedSize:     DefaultMaxFeedSize,
+		retryPolicy:     noRetryPolicy,
+
+		breakerThreshold:   DefaultBreakerThreshold,
+		breakerOpenTimeout: DefaultBreakerOpenTimeout,
+
+		parsers: NewDefaultParserRegistry(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.httpClient = newHTTPClient(newNetGuard(s.allowlist))
+	s.hosts = newHostScheduler(s.hostConcurrency, s.hostMinInterval, s.hostMaxWait)
+	s.breaker = newCircuitBreaker(s.breakerThreshold, s.breakerOpenTimeout)
+	return s
+}
+
+// Diagnostics はサービスの内部状態（ホストごとのサーキットブレーカー等）を返す
+func (s *RSSService) Diagnostics() models.Diagnostics {
+	return models.Diagnostics{CircuitBreakers: s.breaker.snapshot()}
+}
+
+// newHTTPClient はSSRF対策済みのトランスポートを持つHTTPクライアントを作成する
+func newHTTPClient(guard *netGuard) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// プロキシ経由では実際の接続先アドレスを検査できないため使用しない
+	transport.Proxy = nil
+	transport.DialContext = guard.dialContext(30 * time.Second)
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if trace := fetchTraceFrom(req.Context()); trace != nil {
+				trace.redirect(req)
+			}
+			if len(via) >= 10 {
+				return errTooManyRedirects
+			}
+			return nil
 		},
 	}
 }
 
+// fetchJob はワーカーに渡す1件分の取得対象
+type fetchJob struct {
+	index     int // リクエスト内でのURLの位置
+	url       string
+	validator models.FeedValidator
+}
+
+// feedResult は1つのURLに対する取得・パース結果（feed・err・notModifiedのいずれか1つが設定される）
+type feedResult struct {
+	feed        *models.RSSFeed
+	err         *models.ErrorInfo
+	notModified *models.FeedValidator
+	header      http.Header // オリジンのレスポンスヘッダー（キャッシュ判定に使用）
+
+	retryable  bool          // 一時的な失敗で再試行の対象となる
+	retryAfter time.Duration // オリジンがRetry-Afterで指定した待機時間
+	fetchInfo  *models.FetchInfo
+	movedTo    string // 恒久的なリダイレクトの移転先
+	attempts   int
+	page       *htmlPage // フィードではなくHTMLページだった場合（自動検出に使用）
+}
+
 func (s *RSSService) ParseFeeds(ctx context.Context, urls []string) ([]models.RSSFeed, []models.ErrorInfo) {
+	resp := s.Parse(ctx, models.ParseRequest{URLs: urls})
+	return resp.Feeds, resp.Errors
+}
+
+// Parse はリクエストされた全URLをワーカープールで並列取得・パースする
+// 同時実行数はサービスの上限以内で、リクエストのMaxConcurrencyにより引き下げ可能
+// 結果はリクエストのURL順に並べて返す
+func (s *RSSService) Parse(ctx context.Context, req models.ParseRequest) models.ParseResponse {
+	urls := req.URLs
 	if len(urls) == 0 {
-		return nil, nil
-	}
-	feeds := make([]models.RSSFeed, 0, len(urls))
-	errors := make([]models.ErrorInfo, 0)
-	ch := make(chan struct {
-		feed *models.RSSFeed
-		err  *models.ErrorInfo
-	}, len(urls))
-
-	for _, url := range urls {
-		go func(u string) {
-			// URLバリデーション
-			if u == "" {
-				ch <- struct {
-					feed *models.RSSFeed
-					err  *models.ErrorInfo
-				}{
-					feed: nil,
-					err:  &models.ErrorInfo{URL: u, Message: "URLが空です"},
-				}
-				return
-			}
+		return models.ParseResponse{}
+	}
 
-			// HTTP GETリクエスト作成
-			req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
-			if err != nil {
-				ch <- struct {
-					feed *models.RSSFeed
-					err  *models.ErrorInfo
-				}{
-					feed: nil,
-					err:  &models.ErrorInfo{URL: u, Message: fmt.Sprintf("リクエスト作成失敗: %v", err)},
-				}
-				return
-			}
+	validators := make(map[string]models.FeedValidator, len(req.Validators))
+	for _, v := range req.Validators {
+		validators[v.URL] = v
+	}
 
-			// User-Agentヘッダー設定
-			req.Header.Set("User-Agent", "feed-parallel-parse-api/1.0 (RSS Reader)")
-
-			// HTTP GETリクエスト実行
-			resp, err := s.httpClient.Do(req)
-			if err != nil {
-				ch <- struct {
-					feed *models.RSSFeed
-					err  *models.ErrorInfo
-				}{
-					feed: nil,
-					err:  &models.ErrorInfo{URL: u, Message: fmt.Sprintf("HTTP取得失敗: %v", err)},
-				}
-				return
-			}
-			defer resp.Body.Close()
-
-			// HTTPステータスコードチェック
-			if resp.StatusCode != http.StatusOK {
-				ch <- struct {
-					feed *models.RSSFeed
-					err  *models.ErrorInfo
-				}{
-					feed: nil,
-					err:  &models.ErrorInfo{URL: u, Message: fmt.Sprintf("HTTPエラー: %d %s", resp.StatusCode, resp.Status)},
-				}
-				return
-			}
+	workers := s.concurrency(req.MaxConcurrency, len(urls))
+	jobs := make(chan fetchJob)
+	fetched := make([]feedResult, len(urls))
 
-			// レスポンスボディ読み取り
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				ch <- struct {
-					feed *models.RSSFeed
-					err  *models.ErrorInfo
-				}{
-					feed: nil,
-					err:  &models.ErrorInfo{URL: u, Message: fmt.Sprintf("ボディ読み取り失敗: %v", err)},
-				}
-				return
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				fetched[job.index] = s.loadFeed(ctx, job.url, job.validator)
 			}
+		}()
+	}
+	for i, url := range urls {
+		jobs <- fetchJob{index: i, url: url, validator: validators[url]}
+	}
+	close(jobs)
+	wg.Wait()
 
-			// RSSパース（既存のパーサーを使用）
-			parser := gofeed.NewParser()
-			feed, err := parser.ParseString(string(body))
-			if err != nil {
-				ch <- struct {
-					feed *models.RSSFeed
-					err  *models.ErrorInfo
-				}{
-					feed: nil,
-					err:  &models.ErrorInfo{URL: u, Message: fmt.Sprintf("パース失敗: %v", err)},
-				}
-				return
-			}
+	opts := newArticleOptions(req)
+	for i := range fetched {
+		if fetched[i].feed != nil {
+			fetched[i].feed = transformFeed(fetched[i].feed, opts)
+		}
+	}
+	return buildResponse(urls, fetched)
+}
 
-			// RSSFeed変換（feed.FeedLinkまたはrequested URLからFeedURLを設定）
-			rssFeed := feedToRSSFeed(feed, u)
-			ch <- struct {
-				feed *models.RSSFeed
-				err  *models.ErrorInfo
-			}{
-				feed: rssFeed,
-				err:  nil,
-			}
-		}(url)
+// buildResponse はURL順の取得結果からレスポンスを組み立てる
+// feeds/errors/notModifiedはステータスごとの一覧、resultsはリクエストと1対1で対応する一覧
+func buildResponse(urls []string, fetched []feedResult) models.ParseResponse {
+	resp := models.ParseResponse{
+		Feeds:   make([]models.RSSFeed, 0, len(urls)),
+		Errors:  make([]models.ErrorInfo, 0),
+		Results: make([]models.FeedResult, 0, len(urls)),
+	}
+	for i, result := range fetched {
+		entry := models.FeedResult{RequestedURL: urls[i], MovedTo: result.movedTo}
+		switch {
+		case result.err != nil:
+			resp.Errors = append(resp.Errors, *result.err)
+			entry.Status = models.ResultStatusError
+			entry.Error = result.err
+		case result.notModified != nil:
+			resp.NotModified = append(resp.NotModified, *result.notModified)
+			entry.Status = models.ResultStatusNotModified
+			entry.Validator = result.notModified
+		default:
+			resp.Feeds = append(resp.Feeds, *result.feed)
+			entry.Status = models.ResultStatusOK
+			entry.Feed = result.feed
+		}
+		resp.Results = append(resp.Results, entry)
 	}
+	return resp
+}
 
-	for i := 0; i < len(urls); i++ {
-		result := <-ch
-		if result.err != nil {
-			errors = append(errors, *result.err)
-		} else {
-			feeds = append(feeds, *result.feed)
+// concurrency はワーカー数を決定する
+// リクエスト指定値はサービス上限を超えられず、URL数より多くのワーカーは起動しない
+func (s *RSSService) concurrency(requested, total int) int {
+	n := s.maxConcurrency
+	if requested > 0 && requested < n {
+		n = requested
+	}
+	if total < n {
+		n = total
+	}
+	return n
+}
+
+// fetchShared は同一URL・同一バリデータで実行中の取得があれば、その結果を共有する
+// 同じリクエスト内の重複URLや、並行するAPI呼び出しからの同じフィードの取得を1回にまとめる
+func (s *RSSService) fetchShared(ctx context.Context, u string, validator models.FeedValidator) feedResult {
+	key := u + "\x00" + validator.ETag + "\x00" + validator.LastModified
+	result, err := s.flights.do(ctx, key, func(ctx context.Context) feedResult {
+		return s.fetchDiscovering(ctx, u, validator)
+	})
+	if err != nil {
+		return feedResult{err: newErrorInfo(u, classifyFetchError(ctx, err), fmt.Sprintf("HTTP取得失敗: %v", err), err)}
+	}
+	return result
+}
+
+// fetchOnce は1つのURLを1回だけ取得してパースする
+// validatorにETag/Last-Modifiedが設定されている場合は条件付きGETを行う
+// probeの場合は推測したURLの試行として、結果をサーキットブレーカーに記録しない（存在しないパスの5xx等でホストを遮断しない）
+func (s *RSSService) fetchOnce(ctx context.Context, u string, validator models.FeedValidator, probe bool) (result feedResult) {
+	// URLバリデーション
+	if u == "" {
+		return feedResult{err: newErrorInfo(u, models.ErrorCodeInvalidURL, "URLが空です", nil)}
+	}
+	if err := validateFeedURL(u); err != nil {
+		return feedResult{err: newErrorInfo(u, models.ErrorCodeInvalidURL, fmt.Sprintf("HTTP取得失敗: 無効なURLです: %v", err), err)}
+	}
+
+	// HTTP GETリクエスト作成
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return feedResult{err: newErrorInfo(u, models.ErrorCodeInvalidURL, fmt.Sprintf("リクエスト作成失敗: %v", err), err)}
+	}
+
+	// User-Agentヘッダー設定
+	req.Header.Set("User-Agent", "feed-parallel-parse-api/1.0 (RSS Reader)")
+	// 転送サイズを計測できるよう、gzipの展開はトランスポートに任せず自前で行う
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	// 条件付きGETヘッダー設定
+	if validator.ETag != "" {
+		req.Header.Set("If-None-Match", validator.ETag)
+	}
+	if validator.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validator.LastModified)
+	}
+
+	// ホスト単位の同時接続数・リクエスト間隔の制御
+	release, err := s.hosts.acquire(ctx, req.URL.Host)
+	if err != nil {
+		var rle *rateLimitedError
+		if errors.As(err, &rle) {
+			info := newErrorInfo(u, models.ErrorCodeRateLimited, fmt.Sprintf("レート制限: %v", err), err)
+			info.RetryAfter = retryAfterSeconds(rle.retryAfter)
+			return feedResult{err: info}
 		}
+		return feedResult{err: newErrorInfo(u, classifyFetchError(ctx, err), fmt.Sprintf("HTTP取得失敗: %v", err), err)}
 	}
-	close(ch)
-	return feeds, errors
+	defer release()
+
+	// 連続して失敗しているホストへは待たずに失敗させる
+	if err := s.breaker.allow(req.URL.Host); err != nil {
+		var coe *circuitOpenError
+		errors.As(err, &coe)
+		info := newErrorInfo(u, models.ErrorCodeCircuitOpen, fmt.Sprintf("サーキットオープン: %v", err), err)
+		info.RetryAfter = retryAfterSeconds(coe.retryAfter)
+		return feedResult{err: info}
+	}
+
+	// HTTP GETリクエスト実行（計測情報はどの結果にも付与する）
+	traceCtx, trace := withFetchTrace(ctx, u)
+	defer func() {
+		result.fetchInfo = trace.finish()
+		if result.err == nil {
+			result.movedTo = permanentRedirectTarget(result.fetchInfo)
+		}
+	}()
+	resp, err := s.httpClient.Do(req.WithContext(traceCtx))
+	if !probe {
+		s.recordHostOutcome(ctx, req.URL.Host, resp, err)
+	}
+	if err != nil {
+		var blocked *blockedAddressError
+		if errors.As(err, &blocked) {
+			return feedResult{err: newErrorInfo(u, models.ErrorCodeBlocked, fmt.Sprintf("アクセス拒否: %v", blocked), err)}
+		}
+		return feedResult{
+			err:       newErrorInfo(u, classifyFetchError(ctx, err), fmt.Sprintf("HTTP取得失敗: %v", err), err),
+			retryable: ctx.Err() == nil && isRetryableFetchError(err),
+		}
+	}
+	defer resp.Body.Close()
+	trace.resp = resp
+
+	// 429/503のRetry-Afterに従い、以降の同一ホストへのリクエストを控える
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok && resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter, ok = defaultRateLimitBackoff, true
+		}
+		if ok {
+			s.hosts.penalize(req.URL.Host, retryAfter)
+			info := httpStatusErrorInfo(u, resp)
+			info.RetryAfter = retryAfterSeconds(retryAfter)
+			return feedResult{err: info, retryable: s.retryPolicy.retryableStatus(resp.StatusCode), retryAfter: retryAfter}
+		}
+	}
+
+	// 304 Not Modified: 前回から変更なし（新しいバリデータが無ければ送信したものを引き継ぐ）
+	if resp.StatusCode == http.StatusNotModified {
+		nm := models.FeedValidator{URL: u, ETag: validator.ETag, LastModified: validator.LastModified}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			nm.ETag = etag
+		}
+		if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+			nm.LastModified = lastModified
+		}
+		return feedResult{notModified: &nm, header: resp.Header}
+	}
+
+	// HTTPステータスコードチェック
+	if resp.StatusCode != http.StatusOK {
+		return feedResult{err: httpStatusErrorInfo(u, resp), retryable: s.retryPolicy.retryableStatus(resp.StatusCode)}
+	}
+
+	// Content-Lengthで上限超過が分かる場合は読み取らずにエラーとする
+	gzipped := strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip")
+	if !gzipped && resp.ContentLength > s.maxFeedSize {
+		return feedResult{err: tooLargeError(u, &feedTooLargeError{limit: s.maxFeedSize})}
+	}
+
+	// gzipの場合は転送サイズを数えながら展開する（上限は展開後のサイズに対して適用される）
+	var decoded io.Reader = resp.Body
+	if gzipped {
+		trace.wire = &countingReader{r: resp.Body}
+		gz, err := gzip.NewReader(trace.wire)
+		if err != nil {
+			return feedResult{
+				err:       newErrorInfo(u, models.ErrorCodeReadError, fmt.Sprintf("ボディ読み取り失敗: %v", err), err),
+				retryable: ctx.Err() == nil && !errors.Is(err, gzip.ErrHeader),
+			}
+		}
+		defer gz.Close()
+		decoded = gz
+	}
+
+	// RSSパース（ボディを文字列にコピーせず、上限サイズを検査しながらストリームで読み取る）
+	body := newLimitedBody(decoded, s.maxFeedSize)
+	trace.body = body
+	content := bufio.NewReader(body)
+	var rssFeed *models.RSSFeed
+	var page *htmlPage
+	if isHTMLDocument(content) {
+		// フィードではなくHTMLページの場合は、フィードへのリンクを探す（自動検出）
+		page, err = parseHTMLPage(content, resp.Request.URL)
+	} else {
+		// 先頭部分から形式を判定し、登録されたパーサーでパースする
+		rssFeed, err = s.parsers.Parse(ctx, FeedSource{URL: u, FinalURL: resp.Request.URL.String(), Header: resp.Header, Body: content})
+	}
+	if body.exceeded {
+		return feedResult{err: tooLargeError(u, &feedTooLargeError{limit: s.maxFeedSize})}
+	}
+	if body.readErr != nil {
+		return feedResult{err: newErrorInfo(u, models.ErrorCodeReadError, fmt.Sprintf("ボディ読み取り失敗: %v", body.readErr), body.readErr), retryable: ctx.Err() == nil}
+	}
+	if err != nil {
+		return feedResult{err: newErrorInfo(u, models.ErrorCodeParseError, fmt.Sprintf("パース失敗: %v", err), err)}
+	}
+	if page != nil {
+		return feedResult{page: page, header: resp.Header}
+	}
+
+	// FeedURLはフィード内の記載（feed.FeedLink、feed_url）を優先し、無ければrequested URLを設定
+	if rssFeed.FeedURL == "" {
+		rssFeed.FeedURL = u
+	}
+	rssFeed.ETag = resp.Header.Get("ETag")
+	rssFeed.LastModified = resp.Header.Get("Last-Modified")
+	return feedResult{feed: rssFeed, header: resp.Header}
+}
+
+// recordHostOutcome はリクエスト結果をサーキットブレーカーに記録する
+// 接続エラー・タイムアウト・5xxをホストの失敗とし、それ以外の応答はホストが稼働しているとみなす
+func (s *RSSService) recordHostOutcome(ctx context.Context, host string, resp *http.Response, err error) {
+	var blocked *blockedAddressError
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.As(err, &blocked)):
+		s.breaker.abandon(host)
+	case err != nil || resp.StatusCode >= 500:
+		s.breaker.failure(host)
+	default:
+		s.breaker.success(host)
+	}
+}
+
+// tooLargeError はサイズ超過のErrorInfoを作成する
+func tooLargeError(u string, err *feedTooLargeError) *models.ErrorInfo {
+	return newErrorInfo(u, models.ErrorCodeTooLarge, fmt.Sprintf("サイズ超過: %v", err), err)
 }
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"feed-parallel-parse-api/pkg/models"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrInvalidSanitizePolicy はParseRequest.Sanitizeが未知の方針であることを表す
+var ErrInvalidSanitizePolicy = errors.New("sanitizeにはstrip-all、safe-basic、safe-richのいずれかを指定してください")
+
+// sanitizePolicy は残す要素と要素ごとに残す属性の許可リスト
+// textOnlyの場合はタグをすべて除去し、空白を詰めたテキストのみにする
+type sanitizePolicy struct {
+	elements map[atom.Atom][]string
+	textOnly bool
+}
+
+// basicElements はsafe-basicで残す要素と属性
+var basicElements = map[atom.Atom][]string{
+	atom.P: nil, atom.Br: nil,
+	atom.B: nil, atom.Strong: nil, atom.I: nil, atom.Em: nil, atom.U: nil, atom.S: nil, atom.Strike: nil,
+	atom.Del: nil, atom.Ins: nil, atom.Small: nil, atom.Mark: nil, atom.Sub: nil, atom.Sup: nil,
+	atom.A:  {"href", "title"},
+	atom.Ul: nil, atom.Ol: {"start"}, atom.Li: nil,
+	atom.Blockquote: {"cite"}, atom.Q: {"cite"}, atom.Cite: nil,
+	atom.Code: nil, atom.Pre: nil, atom.Abbr: {"title"},
+}
+
+// richElements はsafe-richでbasicElementsに加えて残す要素と属性
+var richElements = map[atom.Atom][]string{
+	atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil,
+	atom.Img:    {"src", "alt", "title", "width", "height"},
+	atom.Figure: nil, atom.Figcaption: nil, atom.Hr: nil,
+	atom.Table: nil, atom.Caption: nil, atom.Thead: nil, atom.Tbody: nil, atom.Tfoot: nil, atom.Tr: nil,
+	atom.Th: {"colspan", "rowspan", "scope"}, atom.Td: {"colspan", "rowspan"},
+	atom.Dl: nil, atom.Dt: nil, atom.Dd: nil,
+	atom.Div: nil, atom.Span: nil, atom.Details: nil, atom.Summary: nil,
+}
+
+// droppedElements は内容ごと除去する要素（スクリプト・埋め込み・フォーム等）
+// 許可リストに無いその他の要素はタグのみ除去し、内容は残す
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Frame: true, atom.Frameset: true, atom.Object: true, atom.Embed: true, atom.Applet: true,
+	atom.Audio: true, atom.Video: true, atom.Source: true, atom.Track: true, atom.Canvas: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+	atom.Svg: true, atom.Math: true,
+	atom.Head: true, atom.Title: true, atom.Meta: true, atom.Link: true, atom.Base: true,
+}
+
+// urlAttributes はURLとして解決・検査する属性
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true}
+
+var sanitizePolicies = map[string]*sanitizePolicy{
+	models.SanitizeStripAll:  {textOnly: true},
+	models.SanitizeSafeBasic: {elements: basicElements},
+	models.SanitizeSafeRich:  {elements: mergeElements(basicElements, richElements)},
+}
+
+// defaultSanitizePolicy はParseRequest.Sanitizeが省略された場合の方針
+const defaultSanitizePolicy = models.SanitizeSafeBasic
+
+func mergeElements(sets ...map[atom.Atom][]string) map[atom.Atom][]string {
+	merged := make(map[atom.Atom][]string)
+	for _, set := range sets {
+		for a, attrs := range set {
+			merged[a] = attrs
+		}
+	}
+	return merged
+}
+
+// ValidateSanitizePolicy はサニタイズ方針が既知の値（または省略）かを検査する
+func ValidateSanitizePolicy(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := sanitizePolicies[name]; !ok {
+		return ErrInvalidSanitizePolicy
+	}
+	return nil
+}
+
+// lookupSanitizePolicy はサニタイズ方針を返す（省略・未知の値の場合はデフォルト）
+func lookupSanitizePolicy(name string) *sanitizePolicy {
+	if p, ok := sanitizePolicies[name]; ok {
+		return p
+	}
+	return sanitizePolicies[defaultSanitizePolicy]
+}
+
+// sanitize はHTMLの断片を方針に従って無害化する（相対URLはbaseを基準に絶対URLにする）
+func (p *sanitizePolicy) sanitize(src, base string) string {
+	out := p.renderFragment(src, base)
+	if p.textOnly {
+		return html.EscapeString(collapseSpace(out))
+	}
+	return strings.TrimSpace(out)
+}
+
+// plainText はHTMLのタグを除去し、文字参照を展開して空白を詰めたテキストを返す
+func plainText(src string) string {
+	return collapseSpace(sanitizePolicies[models.SanitizeStripAll].renderFragment(src, ""))
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// renderFragment はHTMLの断片をパースし、方針に従って出力する（textOnlyの場合はエスケープ前のテキスト）
+func (p *sanitizePolicy) renderFragment(src, base string) string {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		p.render(&b, n, base)
+	}
+	return b.String()
+}
+
+func (p *sanitizePolicy) render(b *strings.Builder, n *html.Node, base string) {
+	switch n.Type {
+	case html.TextNode:
+		if p.textOnly {
+			b.WriteString(n.Data)
+		} else {
+			b.WriteString(html.EscapeString(n.Data))
+		}
+		return
+	case html.ElementNode:
+	default:
+		return // コメント・DOCTYPE等は除去する
+	}
+	if droppedElements[n.DataAtom] {
+		return
+	}
+
+	allowedAttrs, allowed := p.elements[n.DataAtom]
+	if !allowed {
+		// タグのみ除去する（テキストのみの場合はブロック要素の区切りが詰まらないよう空白を入れる）
+		p.renderChildren(b, n, base)
+		if p.textOnly {
+			b.WriteString(" ")
+		}
+		return
+	}
+
+	attrs, ok := sanitizeAttributes(n, allowedAttrs, base)
+	if !ok {
+		return
+	}
+	b.WriteString("<" + n.Data)
+	for _, a := range attrs {
+		b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+	b.WriteString(">")
+	if n.DataAtom == atom.Br || n.DataAtom == atom.Img || n.DataAtom == atom.Hr {
+		return // 空要素
+	}
+	p.renderChildren(b, n, base)
+	b.WriteString("</" + n.Data + ">")
+}
+
+func (p *sanitizePolicy) renderChildren(b *strings.Builder, n *html.Node, base string) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.render(b, c, base)
+	}
+}
+
+// sanitizeAttributes は許可された属性のみ残し、URLは絶対URLに解決して安全なスキームかを検査する
+// 要素自体を除去すべき場合（srcが無い・1x1のトラッキング画像）はfalseを返す
+func sanitizeAttributes(n *html.Node, allowed []string, base string) ([]html.Attribute, bool) {
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !slices.Contains(allowed, a.Key) {
+			continue
+		}
+		if urlAttributes[a.Key] {
+			u, ok := safeURL(a.Val, base, a.Key == "href")
+			if !ok {
+				continue
+			}
+			a.Val = u
+		}
+		if n.DataAtom == atom.Img && (a.Key == "width" || a.Key == "height") && strings.TrimSpace(a.Val) == "1" {
+			return nil, false
+		}
+		attrs = append(attrs, a)
+	}
+	switch n.DataAtom {
+	case atom.Img:
+		if !hasAttribute(attrs, "src") {
+			return nil, false
+		}
+	case atom.A:
+		if hasAttribute(attrs, "href") {
+			attrs = append(attrs, html.Attribute{Key: "rel", Val: "nofollow noopener noreferrer"})
+		}
+	}
+	return attrs, true
+}
+
+// safeURL はURLを絶対URLに解決し、http/https（リンクの場合はmailtoも）のみ許可する
+func safeURL(raw, base string, link bool) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	resolved := resolveURL(base, raw)
+	u, err := url.Parse(resolved)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.String(), true
+	case "mailto":
+		return u.String(), link
+	case "":
+		// 基準URLが無く相対URLのまま（同じページ内のアンカー等）
+		return u.String(), link && u.Host == "" && !strings.HasPrefix(raw, "//")
+	}
+	return "", false
+}
+
+func hasAttribute(attrs []html.Attribute, key string) bool {
+	return slices.ContainsFunc(attrs, func(a html.Attribute) bool { return a.Key == key })
+}
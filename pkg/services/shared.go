@@ -0,0 +1,25 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestTimeout はAPI1リクエストの処理全体（再試行を含む）の上限時間
+const RequestTimeout = 25 * time.Second
+
+// SharedService はAPIハンドラーが使うRSSService（ウォームスタートしたインスタンス内でキャッシュ・サーキットブレーカーを共有する）
+// Vercelでは api/*.go がそれぞれ別のサーバーレス関数となりメモリを共有しないため、状態は関数（とそのインスタンス）ごとになる
+var SharedService = sync.OnceValue(func() *RSSService {
+	opts := []RSSServiceOption{
+		WithCache(NewLRUCache(DefaultCacheSize)),
+		WithRetryPolicy(DefaultRetryPolicy),
+	}
+	// ローカル開発用: FEED_FETCH_ALLOWLIST（カンマ区切りのホスト名・IP・CIDR）はSSRF対策の対象外とする
+	if allowlist := os.Getenv("FEED_FETCH_ALLOWLIST"); allowlist != "" {
+		opts = append(opts, WithAllowedHosts(strings.Split(allowlist, ",")...))
+	}
+	return NewRSSService(opts...)
+})
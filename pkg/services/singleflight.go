@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup は同一キーの実行中の取得処理を1つにまとめる（singleflight）
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall は実行中または完了した1回分の取得処理
+type flightCall struct {
+	done   chan struct{}
+	result feedResult
+}
+
+// do はkeyに対して実行中の処理があればその完了を待って結果を共有し、なければfnを実行する
+// fnは呼び出し元のキャンセルの影響を受けない（期限は引き継ぐ）コンテキストで実行されるため、
+// 待機中の呼び出し元がキャンセルしても他の待機者には結果が届く
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) feedResult) (feedResult, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, inFlight := g.calls[key]
+	if !inFlight {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		fctx, cancel := detach(ctx)
+		go func() {
+			defer cancel()
+			call.result = fn(fctx)
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result.clone(), nil
+	case <-ctx.Done():
+		return feedResult{}, ctx.Err()
+	}
+}
+
+// clone は共有された結果を呼び出し元ごとに変更できるようコピーする
+func (r feedResult) clone() feedResult {
+	if r.feed != nil {
+		feed := *r.feed
+		r.feed = &feed
+	}
+	if r.err != nil {
+		e := *r.err
+		r.err = &e
+	}
+	if r.notModified != nil {
+		nm := *r.notModified
+		r.notModified = &nm
+	}
+	return r
+}
+
+// detach はキャンセルを伝播せず、期限のみを引き継いだコンテキストを作成する
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return detached, func() {}
+}
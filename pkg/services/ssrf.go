@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// blockedPrefixes はフィード取得での接続を禁止するアドレス範囲
+// ループバック・リンクローカル（クラウドのメタデータエンドポイントを含む）・プライベート・予約済みアドレス
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // 「このネットワーク」
+	netip.MustParsePrefix("10.0.0.0/8"),      // プライベート
+	netip.MustParsePrefix("100.64.0.0/10"),   // キャリアグレードNAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // ループバック
+	netip.MustParsePrefix("169.254.0.0/16"),  // リンクローカル（169.254.169.254等のメタデータ）
+	netip.MustParsePrefix("172.16.0.0/12"),   // プライベート
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETFプロトコル割り当て
+	netip.MustParsePrefix("192.0.2.0/24"),    // ドキュメント用
+	netip.MustParsePrefix("192.168.0.0/16"),  // プライベート
+	netip.MustParsePrefix("198.18.0.0/15"),   // ベンチマーク用
+	netip.MustParsePrefix("198.51.100.0/24"), // ドキュメント用
+	netip.MustParsePrefix("203.0.113.0/24"),  // ドキュメント用
+	netip.MustParsePrefix("224.0.0.0/4"),     // マルチキャスト
+	netip.MustParsePrefix("240.0.0.0/4"),     // 予約済み・ブロードキャスト
+	netip.MustParsePrefix("::/128"),          // 未指定
+	netip.MustParsePrefix("::1/128"),         // ループバック
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64（IPv4アドレスを埋め込めるため）
+	netip.MustParsePrefix("100::/64"),        // 破棄用
+	netip.MustParsePrefix("2001:db8::/32"),   // ドキュメント用
+	netip.MustParsePrefix("fc00::/7"),        // ユニークローカル
+	netip.MustParsePrefix("fe80::/10"),       // リンクローカル
+	netip.MustParsePrefix("ff00::/8"),        // マルチキャスト
+}
+
+// blockedAddressError は禁止されたアドレスへの接続を拒否したことを表す
+type blockedAddressError struct {
+	addr netip.Addr
+}
+
+func (e *blockedAddressError) Error() string {
+	return fmt.Sprintf("内部ネットワークのアドレス %s へのアクセスは許可されていません", e.addr)
+}
+
+// netGuard はSSRF対策として接続先アドレスを検査する
+// 検査はDNS解決後の実際の接続先に対して行うため、リダイレクトやDNSリバインディングにも有効
+type netGuard struct {
+	allowedHosts    map[string]bool
+	allowedPrefixes []netip.Prefix
+}
+
+// newNetGuard は許可リスト（ホスト名・IPアドレス・CIDR表記）からnetGuardを作成する
+func newNetGuard(allowlist []string) *netGuard {
+	g := &netGuard{allowedHosts: make(map[string]bool)}
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			g.allowedPrefixes = append(g.allowedPrefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			g.allowedPrefixes = append(g.allowedPrefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			g.allowedHosts[entry] = true
+		}
+	}
+	return g
+}
+
+// check は接続先アドレスが許可されているか判定する
+func (g *netGuard) check(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowedPrefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	if !addr.IsValid() {
+		return &blockedAddressError{addr: addr}
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return &blockedAddressError{addr: addr}
+		}
+	}
+	return nil
+}
+
+// dialContext は許可リストのホスト以外への接続時に、接続直前のアドレス検査を行うDialContext関数を返す
+func (g *netGuard) dialContext(timeout time.Duration) func(ctx context.Context, network, address string) (net.Conn, error) {
+	plain := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	guarded := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			return g.check(ap.Addr())
+		},
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err == nil && g.allowedHosts[strings.ToLower(host)] {
+			return plain.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+}
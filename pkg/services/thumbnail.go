@@ -0,0 +1,149 @@
+package services
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// imageExtensions はtype・mediumが無いmedia:contentを画像とみなす拡張子
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".avif": true,
+}
+
+// thumbnail は記事のサムネイル画像（サイズは分かる場合のみ）
+// inHTMLは本文・要約の<img>から取得したか（相対URLは記事のURLを基準に解決する）
+type thumbnail struct {
+	url           string
+	width, height int
+	inHTML        bool
+}
+
+// resolve はサムネイルのURLを絶対URLにする（本文中の画像は記事のURL、それ以外はフィードのリンクを基準とする）
+func (t thumbnail) resolve(siteURL, articleURL string) string {
+	if t.inHTML {
+		return resolveURL(resolveURL(siteURL, articleURL), t.url)
+	}
+	return resolveURL(siteURL, t.url)
+}
+
+// itemThumbnail はフィードの記事から最適なサムネイルを選ぶ
+// 優先順位: media:thumbnail → 画像のmedia:content → 画像のenclosure → item.Image（iTunes画像等）→ 本文・要約の最初の<img>
+// 同じ種類の候補が複数ある場合は最も大きいものを選ぶ
+func itemThumbnail(item *gofeed.Item) thumbnail {
+	media := item.Extensions["media"]
+	var thumbnails, contents []ext.Extension
+	// 記事直下の要素とmedia:group内の要素を同じように扱う
+	for _, group := range append([]ext.Extension{{Children: media}}, media["group"]...) {
+		thumbnails = append(thumbnails, group.Children["thumbnail"]...)
+		for _, c := range group.Children["content"] {
+			thumbnails = append(thumbnails, c.Children["thumbnail"]...)
+			if isMediaImage(c) {
+				contents = append(contents, c)
+			}
+		}
+	}
+	if t := largestMediaImage(thumbnails); t.url != "" {
+		return t
+	}
+	if t := largestMediaImage(contents); t.url != "" {
+		return t
+	}
+	for _, enc := range item.Enclosures {
+		if enc != nil && enc.URL != "" && strings.HasPrefix(enc.Type, "image/") {
+			return thumbnail{url: enc.URL}
+		}
+	}
+	html := htmlThumbnail(item.Content)
+	if html.url == "" {
+		html = htmlThumbnail(item.Description)
+	}
+	// gofeedのitem.Imageは本文の最初の<img>から補われる場合がある（その場合はサイズ・トラッキング画像の判定ができる本文側を使う）
+	if item.Image != nil && item.Image.URL != "" && !strings.Contains(item.Content+item.Description, item.Image.URL) {
+		return thumbnail{url: item.Image.URL}
+	}
+	return html
+}
+
+// isMediaImage はmedia:contentが画像かを返す（medium・type、どちらも無い場合はURLの拡張子で判定）
+func isMediaImage(e ext.Extension) bool {
+	if medium := e.Attrs["medium"]; medium != "" {
+		return medium == "image"
+	}
+	if mimeType := e.Attrs["type"]; mimeType != "" {
+		return strings.HasPrefix(mimeType, "image/")
+	}
+	u, err := url.Parse(e.Attrs["url"])
+	return err == nil && imageExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// largestMediaImage はmedia:thumbnail・media:contentの候補から面積が最大のもの（サイズ不明なら最初のもの）を返す
+func largestMediaImage(candidates []ext.Extension) thumbnail {
+	var best thumbnail
+	for _, c := range candidates {
+		t := thumbnail{url: strings.TrimSpace(c.Attrs["url"]), width: atoiOrZero(c.Attrs["width"]), height: atoiOrZero(c.Attrs["height"])}
+		if t.url == "" {
+			continue
+		}
+		if best.url == "" || t.width*t.height > best.width*best.height {
+			best = t
+		}
+	}
+	return best
+}
+
+// htmlThumbnail はHTMLの最初の<img>を返す（data: URIと1x1のトラッキング画像は除く）
+func htmlThumbnail(html string) thumbnail {
+	if !strings.Contains(html, "<img") && !strings.Contains(html, "<IMG") {
+		return thumbnail{}
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return thumbnail{}
+	}
+	var found thumbnail
+	doc.Find("img[src]").EachWithBreak(func(_ int, img *goquery.Selection) bool {
+		t := thumbnail{
+			url:    strings.TrimSpace(img.AttrOr("src", "")),
+			width:  atoiOrZero(img.AttrOr("width", "")),
+			height: atoiOrZero(img.AttrOr("height", "")),
+			inHTML: true,
+		}
+		if t.url == "" || strings.HasPrefix(t.url, "data:") || t.width == 1 || t.height == 1 {
+			return true
+		}
+		found = t
+		return false
+	})
+	return found
+}
+
+// atoiOrZero は数値の属性値を整数にする（"640px"等の解釈できない値は0）
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// resolveURL はrefをbaseを基準に絶対URLにする（baseが無い・解釈できない場合はrefのまま）
+func resolveURL(base, ref string) string {
+	if ref == "" || base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	u, err := b.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// xmlURLAttributes はxml:baseの範囲内で絶対URLに解決する属性（atom:linkのhref、enclosure・media:*のurl等）と、開始タグ内でその値を探すパターン
+var xmlURLAttributes = map[string]*regexp.Regexp{
+	"href": regexp.MustCompile(`\shref\s*=\s*("[^"]*"|'[^']*')`),
+	"src":  regexp.MustCompile(`\ssrc\s*=\s*("[^"]*"|'[^']*')`),
+	"url":  regexp.MustCompile(`\surl\s*=\s*("[^"]*"|'[^']*')`),
+}
+
+// xmlBaseAttrPattern は開始タグ内のxml:base属性の値
+var xmlBaseAttrPattern = regexp.MustCompile(`\sxml:base\s*=\s*("[^"]*"|'[^']*')`)
+
+// xmlBaseResolver は要素の入れ子に沿ってxml:baseを追跡し、gofeedが正しく扱える絶対URLに書き換える
+//   - xml:base属性は、取得元URLと親要素のxml:baseを基準に解決したディレクトリの絶対URLにする
+//     （gofeedは文書のURLを知らないため相対のxml:baseを解決できず、またディレクトリでないxml:base
+//     （"https://example.com/wp-atom.php"等）の末尾に"/"を補ってしまう）
+//   - xml:baseの範囲内にある要素のhref・src・url属性は、そのxml:baseを基準に絶対URLにする
+//     （gofeedはatom:linkのhrefやenclosureのurlをxml:baseで解決しない）
+//
+// xml:baseの範囲外のURLはそのまま残し、フィードのリンク・取得元URLを基準に変換時に解決する
+type xmlBaseResolver struct {
+	root  *url.URL   // 取得元URL（解析できない場合はxml:baseを書き換えない）
+	bases []*url.URL // 開いている要素ごとの基準URL（xml:baseの範囲外はnil）
+}
+
+func newXMLBaseResolver(docURL string) *xmlBaseResolver {
+	root, _ := url.Parse(docURL)
+	return &xmlBaseResolver{root: root}
+}
+
+// startTag は開始タグのxml:baseと、その範囲内のURL属性を書き換えたタグを返す（空要素のタグでなければ要素を開く）
+func (b *xmlBaseResolver) startTag(tag []byte, selfClosing bool) []byte {
+	var base *url.URL
+	if len(b.bases) > 0 {
+		base = b.bases[len(b.bases)-1]
+	}
+	if b.root != nil && bytes.Contains(tag, []byte("xml:base")) {
+		if value, ok := xmlAttrValue(tag, xmlBaseAttrPattern); ok {
+			if ref, err := url.Parse(value); err == nil {
+				if base == nil {
+					base = b.root
+				}
+				base = base.ResolveReference(ref)
+				tag = replaceXMLAttr(tag, xmlBaseAttrPattern, "xml:base", base.ResolveReference(&url.URL{Path: "."}).String())
+			}
+		}
+	}
+	if base != nil {
+		for name, pattern := range xmlURLAttributes {
+			value, ok := xmlAttrValue(tag, pattern)
+			if !ok || value == "" {
+				continue
+			}
+			if resolved := resolveURL(base.String(), value); resolved != value {
+				tag = replaceXMLAttr(tag, pattern, name, resolved)
+			}
+		}
+	}
+	if !selfClosing {
+		b.bases = append(b.bases, base)
+	}
+	return tag
+}
+
+// endTag は直近に開いた要素を閉じる
+func (b *xmlBaseResolver) endTag() {
+	if len(b.bases) > 0 {
+		b.bases = b.bases[:len(b.bases)-1]
+	}
+}
+
+// xmlAttrValue は開始タグ内でpatternに一致する属性の値（実体参照を展開し、前後の空白を除いたもの）を返す
+func xmlAttrValue(tag []byte, pattern *regexp.Regexp) (string, bool) {
+	m := pattern.FindSubmatch(tag)
+	if m == nil {
+		return "", false
+	}
+	quoted := m[1]
+	return strings.TrimSpace(html.UnescapeString(string(quoted[1 : len(quoted)-1]))), true
+}
+
+// replaceXMLAttr は開始タグ内でpatternに一致する属性の値をvalueにする
+func replaceXMLAttr(tag []byte, pattern *regexp.Regexp, name, value string) []byte {
+	return pattern.ReplaceAllLiteral(tag, []byte(` `+name+`="`+html.EscapeString(value)+`"`))
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"feed-parallel-parse-api/pkg/models"
+	"io"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/text/transform"
+)
+
+// charRefPattern は数値文字参照（&#11;、&#x0B;等）
+var charRefPattern = regexp.MustCompile(`&#(?:[xX]([0-9a-fA-F]{1,8})|([0-9]{1,10}));`)
+
+// parseXMLFeed はXMLのフィードをボディをコピーせずストリームのままgofeedでパースする
+// 読み取りながら実際のフィードによくあるXMLの誤りを修復し、文書中のxml:baseを解決する
+//   - XMLで使えない文字（制御文字・不正なUTF-8のバイト列、それらの文字参照）を除去する
+//   - ルート要素の終了タグより後ろの内容を切り捨て、実体参照になっていない"&"をエスケープする（xmlStream）
+//
+// gofeedは非厳密モードのため後の2つではパースに失敗しないが、フィードの誤りとして修復し、その内容（Repair*定数）を返す
+func parseXMLFeed(r io.Reader, docURL string) (*gofeed.Feed, []string, error) {
+	filter := &xmlCharFilter{}
+	stream := newXMLStream(transform.NewReader(r, filter), docURL)
+	feed, err := gofeed.NewParser().Parse(stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	io.Copy(io.Discard, stream) // gofeedが読み取らなかったルート要素の後ろの内容も検査する
+	var repairs []string
+	if filter.removed {
+		repairs = append(repairs, models.RepairInvalidCharacters)
+	}
+	if stream.truncated {
+		repairs = append(repairs, models.RepairTrailingGarbage)
+	}
+	if stream.escapedAmpersand {
+		repairs = append(repairs, models.RepairUnescapedAmpersand)
+	}
+	return feed, repairs, nil
+}
+
+// isXMLChar はXML 1.0で使える文字かを返す
+func isXMLChar(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return true
+	case r >= 0x20 && r <= 0xd7ff:
+		return true
+	case r >= 0xe000 && r <= 0xfffd:
+		return true
+	case r >= 0x10000 && r <= utf8.MaxRune:
+		return true
+	}
+	return false
+}
+
+// charRefMaxLen は数値文字参照の最大長（"&#x"・8桁・";"、10進数の場合は"&#"・10桁・";"）
+const charRefMaxLen = 13
+
+// xmlCharFilter はXMLで使えない文字（制御文字・不正なUTF-8のバイト列）と、それを指す文字参照を除去するtransform.Transformer
+// ボディをコピーせずストリームのまま適用でき、除去した場合はremovedをtrueにする
+type xmlCharFilter struct {
+	transform.NopResetter
+	removed bool
+}
+
+func (f *xmlCharFilter) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		n, keep := 1, true
+		if src[nSrc] == '&' && bytes.HasPrefix(src[nSrc+1:], []byte("#")) {
+			ref := charRefPattern.Find(src[nSrc:min(len(src), nSrc+charRefMaxLen)])
+			if ref == nil && !atEOF && len(src)-nSrc < charRefMaxLen {
+				return nDst, nSrc, transform.ErrShortSrc // 文字参照が途中で切れている可能性がある
+			}
+			if ref != nil && bytes.HasPrefix(src[nSrc:], ref) {
+				n, keep = len(ref), validCharRef(ref)
+			}
+		} else {
+			var r rune
+			r, n = utf8.DecodeRune(src[nSrc:])
+			if r == utf8.RuneError && n == 1 && !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc // 文字の途中で切れている
+			}
+			keep = isXMLChar(r) && !(r == utf8.RuneError && n == 1)
+		}
+		if !keep {
+			f.removed = true
+			nSrc += n
+			continue
+		}
+		if nDst+n > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+n])
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// validCharRef は数値文字参照がXMLで使える文字を指すかを返す
+func validCharRef(ref []byte) bool {
+	m := charRefPattern.FindSubmatch(ref)
+	var code uint64
+	var err error
+	if len(m[1]) > 0 {
+		code, err = strconv.ParseUint(string(m[1]), 16, 32)
+	} else {
+		code, err = strconv.ParseUint(string(m[2]), 10, 32)
+	}
+	return err == nil && code <= utf8.MaxRune && isXMLChar(rune(code))
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+)
+
+// entityRefPattern は"&"から始まる正しい実体参照・文字参照
+var entityRefPattern = regexp.MustCompile(`^&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z_:][A-Za-z0-9_.:-]*);`)
+
+// elementNamePattern は開始タグ・終了タグの要素名
+var elementNamePattern = regexp.MustCompile(`^</?([^\s/>!?]+)`)
+
+// xmlLiteralSections はそのまま読み飛ばすマークアップ（CDATAセクション・コメント・処理命令）の開始と終端
+var xmlLiteralSections = []struct{ start, end string }{
+	{"![CDATA[", "]]>"},
+	{"!--", "-->"},
+	{"?", "?>"},
+}
+
+// xmlStream はXMLのボディを読み取りながら、マークアップ（開始タグ・終了タグ等）の単位で書き換えるio.Reader
+// ボディ全体をメモリ上に読み取らずに、文書中のどこにあるxml:baseも解決し、次の誤りを修復する
+//   - 実体参照になっていない"&"をエスケープする（CDATAセクション・コメント・処理命令内は除く）
+//   - ルート要素の終了タグより後ろの内容（PHPのエラーメッセージ等）を切り捨てる（空白・コメント・処理命令は除く）
+type xmlStream struct {
+	r     *bufio.Reader
+	bases *xmlBaseResolver
+	out   []byte // 書き換え済みで未読の内容
+	err   error
+
+	root       string // ルート要素の名前
+	rootClosed bool   // ルート要素の終了タグを読み取ったか
+	// escapedAmpersand・truncated は"&"をエスケープしたか・ルート要素の後ろの内容を切り捨てたか
+	escapedAmpersand bool
+	truncated        bool
+}
+
+func newXMLStream(r io.Reader, docURL string) *xmlStream {
+	return &xmlStream{r: bufio.NewReader(r), bases: newXMLBaseResolver(docURL)}
+}
+
+func (s *xmlStream) Read(p []byte) (int, error) {
+	for len(s.out) == 0 && s.err == nil {
+		s.err = s.next()
+	}
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	if n == 0 {
+		return 0, s.err
+	}
+	return n, nil
+}
+
+// next は次のテキストと、それに続くマークアップを1つ読み取ってoutに追加する
+func (s *xmlStream) next() error {
+	text, err := s.r.ReadBytes('<')
+	if err == nil {
+		text = text[:len(text)-1]
+	}
+	if s.rootClosed && len(bytes.TrimSpace(text)) > 0 {
+		return s.truncate()
+	}
+	s.out = append(s.out, s.escapeAmpersands(text)...)
+	if err != nil {
+		return err
+	}
+
+	head, _ := s.r.Peek(len("![CDATA["))
+	for _, l := range xmlLiteralSections {
+		if bytes.HasPrefix(head, []byte(l.start)) {
+			if s.rootClosed && l.end == "]]>" {
+				return s.truncate()
+			}
+			section, err := s.readSection(len(l.start), l.end)
+			s.out = append(s.out, section...)
+			return err
+		}
+	}
+	if s.rootClosed {
+		return s.truncate()
+	}
+	tag, err := s.readTag()
+	tag = s.escapeAmpersands(tag)
+	switch {
+	case err != nil, bytes.HasPrefix(head, []byte("!")): // 途中で終わったタグ・DOCTYPE宣言
+	case bytes.HasPrefix(head, []byte("/")):
+		s.bases.endTag()
+		if m := elementNamePattern.FindSubmatch(tag); m != nil && string(m[1]) == s.root {
+			s.rootClosed = true
+		}
+	default:
+		if m := elementNamePattern.FindSubmatch(tag); m != nil && s.root == "" {
+			s.root = string(m[1])
+		}
+		tag = s.bases.startTag(tag, bytes.HasSuffix(tag, []byte("/>")))
+	}
+	s.out = append(s.out, tag...)
+	return err
+}
+
+// truncate はルート要素の後ろの残りの内容を読み取らずに切り捨てる
+func (s *xmlStream) truncate() error {
+	s.truncated = true
+	return io.EOF
+}
+
+// escapeAmpersands はテキスト・タグ内の実体参照・文字参照になっていない"&"を"&amp;"にする
+func (s *xmlStream) escapeAmpersands(data []byte) []byte {
+	if bytes.IndexByte(data, '&') < 0 {
+		return data
+	}
+	var out bytes.Buffer
+	out.Grow(len(data))
+	for i, c := range data {
+		if c == '&' && !entityRefPattern.Match(data[i:]) {
+			out.WriteString("&amp;")
+			s.escapedAmpersand = true
+			continue
+		}
+		out.WriteByte(c)
+	}
+	return out.Bytes()
+}
+
+// readSection は"<"に続く、開始（startLenバイト）から終端endまでの内容を"<"を含めて読み取る
+func (s *xmlStream) readSection(startLen int, end string) ([]byte, error) {
+	section := []byte{'<'}
+	for {
+		chunk, err := s.r.ReadBytes(end[len(end)-1])
+		section = append(section, chunk...)
+		if err != nil {
+			return section, err
+		}
+		if len(section) >= 1+startLen+len(end) && bytes.HasSuffix(section, []byte(end)) {
+			return section, nil
+		}
+	}
+}
+
+// readTag は"<"に続く、引用符・DOCTYPE宣言の内部サブセットの外にある">"までを"<"を含めて読み取る
+func (s *xmlStream) readTag() ([]byte, error) {
+	tag := []byte{'<'}
+	var quote byte
+	depth := 0
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return tag, err
+		}
+		tag = append(tag, c)
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '>' && depth <= 0:
+			return tag, nil
+		}
+	}
+}
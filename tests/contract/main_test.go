@@ -0,0 +1,13 @@
+package contract
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain はローカルのテストサーバー（httptest）からフィードを取得できるよう、
+// ハンドラーの共有サービスが初期化される前にSSRF対策の許可リストを設定する
+func TestMain(m *testing.M) {
+	os.Setenv("FEED_FETCH_ALLOWLIST", "127.0.0.1")
+	os.Exit(m.Run())
+}
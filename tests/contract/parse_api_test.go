@@ -11,6 +11,7 @@ import (
 	"feed-parallel-parse-api/pkg/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseHandler_仕様テスト(t *testing.T) {
@@ -70,3 +71,85 @@ func TestParseHandler_FeedURLフィールド存在確認(t *testing.T) {
 		t.Logf("⚠️ フィード取得エラー: %+v (テストは継続)", response.Errors)
 	}
 }
+
+// 不正リクエスト時もエラーコード付きのErrorInfoを返すこと
+func TestParseHandler_不正リクエストはinvalid_requestコードを返す(t *testing.T) {
+	req := httptest.NewRequest("POST", "/parse", bytes.NewBufferString("invalid"))
+	w := httptest.NewRecorder()
+
+	handler.Handler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ParseResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, models.ErrorCodeInvalidRequest, response.Errors[0].Code)
+		assert.False(t, response.Errors[0].Retryable)
+	}
+}
+
+// 記事の詳細フィールド（id/authors/categories/updated/contentHtml/imageUrl等）がOpenAPIのキー名で返ること
+func TestParseHandler_記事の詳細フィールド(t *testing.T) {
+	feed := `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Atom</title>
+<entry>
+<title>Entry</title>
+<id>urn:uuid:entry-1</id>
+<link href="https://example.com/entry/1"/>
+<published>2024-01-02T00:00:00Z</published>
+<updated>2024-01-03T00:00:00Z</updated>
+<summary>要約</summary>
+<content type="html">&lt;p&gt;本文&lt;/p&gt;&lt;img src="https://example.com/1.png"&gt;</content>
+<author><name>Author</name><email>author@example.com</email></author>
+<category term="go"/>
+</entry>
+</feed>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	reqBody, _ := json.Marshal(models.ParseRequest{URLs: []string{server.URL}})
+	req := httptest.NewRequest("POST", "/parse", bytes.NewBuffer(reqBody))
+	w := httptest.NewRecorder()
+
+	handler.Handler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Feeds []struct {
+			Articles []map[string]any `json:"articles"`
+		} `json:"feeds"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Feeds, 1)
+	require.Len(t, response.Feeds[0].Articles, 1)
+	article := response.Feeds[0].Articles[0]
+	assert.Equal(t, "urn:uuid:entry-1", article["id"])
+	assert.Equal(t, "2024-01-02T00:00:00Z", article["pubDate"])
+	assert.Equal(t, "2024-01-03T00:00:00Z", article["updated"])
+	assert.Equal(t, "2024-01-02T00:00:00Z", article["publishedAt"])
+	assert.Equal(t, "2024-01-03T00:00:00Z", article["updatedAt"])
+	assert.Equal(t, "要約", article["summary"])
+	assert.Equal(t, `<p>本文</p><img src="https://example.com/1.png">`, article["contentHtml"])
+	assert.Equal(t, []any{map[string]any{"name": "Author", "email": "author@example.com"}}, article["authors"])
+	assert.Equal(t, []any{"go"}, article["categories"])
+	assert.Equal(t, "https://example.com/1.png", article["imageUrl"])
+}
+
+// 未知のサニタイズ方針はinvalid_requestコードで400を返すこと
+func TestParseHandler_未知のサニタイズ方針は400を返す(t *testing.T) {
+	req := httptest.NewRequest("POST", "/parse", bytes.NewBufferString(`{"urls":[],"sanitize":"none"}`))
+	w := httptest.NewRecorder()
+
+	handler.Handler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ParseResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, models.ErrorCodeInvalidRequest, response.Errors[0].Code)
+	assert.Contains(t, response.Errors[0].Detail, "sanitize")
+}
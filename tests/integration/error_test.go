@@ -6,15 +6,13 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"feed-parallel-parse-api/pkg/services"
-
 	"github.com/stretchr/testify/assert"
 )
 
 // T025: エラー時のErrorInfo格納テスト
 func Testエラー時はErrorInfoに格納される(t *testing.T) {
 	urls := []string{"bad-url"}
-	svc := services.NewRSSService()
+	svc := newTestService()
 	feeds, errors := svc.ParseFeeds(context.Background(), urls)
 	assert.Equal(t, 0, len(feeds), "不正URLはfeedに含まれない")
 	assert.Equal(t, 1, len(errors), "ErrorInfoが1件返る")
@@ -54,7 +52,7 @@ func Test成功とエラーが混在する場合(t *testing.T) {
 		"invalid-url",
 	}
 
-	svc := services.NewRSSService()
+	svc := newTestService()
 	feeds, errors := svc.ParseFeeds(context.Background(), urls)
 
 	// 検証
@@ -0,0 +1,10 @@
+package integration
+
+import "feed-parallel-parse-api/pkg/services"
+
+// newTestService はhttptestのモックサーバー（127.0.0.1）に接続できるRSSServiceを作成する
+// デフォルトではSSRF対策によりループバックアドレスへの接続が拒否されるため、許可リストに追加する
+// テストサーバーはすべて同じホストのため、同一ホストへのリクエスト間隔は空けない（間隔のテストでは個別に指定する）
+func newTestService(opts ...services.RSSServiceOption) *services.RSSService {
+	return services.NewRSSService(append([]services.RSSServiceOption{services.WithAllowedHosts("127.0.0.1"), services.WithHostMinInterval(0)}, opts...)...)
+}
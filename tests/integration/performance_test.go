@@ -2,7 +2,6 @@ package integration
 
 import (
 	"context"
-	"feed-parallel-parse-api/pkg/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -37,7 +36,7 @@ func Test大量URLでもタイムアウトしない(t *testing.T) {
 		urls[i] = server.URL
 	}
 
-	svc := services.NewRSSService()
+	svc := newTestService()
 	start := time.Now()
 	feeds, errors := svc.ParseFeeds(context.Background(), urls)
 	duration := time.Since(start)
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const richRSS2Feed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+<title>RSS2</title>
+<link>https://example.com/</link>
+<item>
+<title>記事1</title>
+<link>https://example.com/1</link>
+<guid isPermaLink="false">tag:example.com,2024:1</guid>
+<pubDate>Tue, 02 Jan 2024 09:00:00 +0900</pubDate>
+<description>要約</description>
+<content:encoded><![CDATA[<p>本文<strong>全文</strong></p>]]></content:encoded>
+<dc:creator>山田太郎</dc:creator>
+<category>Go</category>
+<category>RSS</category>
+<media:content url="https://example.com/1.jpg" medium="image"/>
+</item>
+<item>
+<title>記事2</title>
+<link>https://example.com/2</link>
+<author>editor@example.com (Editor)</author>
+</item>
+</channel>
+</rss>`
+
+const richRDFFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel rdf:about="https://example.jp/">
+<title>RDF</title>
+<link>https://example.jp/</link>
+</channel>
+<item rdf:about="https://example.jp/entry/1">
+<title>日記</title>
+<link>https://example.jp/entry/1</link>
+<description>要約</description>
+<content:encoded><![CDATA[<p>本文</p>]]></content:encoded>
+<dc:creator>hatena</dc:creator>
+<dc:subject>日記</dc:subject>
+<dc:date>2024-01-02T09:00:00+09:00</dc:date>
+</item>
+</rdf:RDF>`
+
+const richAtomFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Atom</title>
+<id>urn:uuid:feed</id>
+<updated>2024-01-03T00:00:00Z</updated>
+<author><name>Feed Author</name><email>feed@example.com</email></author>
+<entry>
+<title>エントリー1</title>
+<id>urn:uuid:entry-1</id>
+<link href="https://example.com/entry/1"/>
+<published>2024-01-02T00:00:00Z</published>
+<updated>2024-01-03T00:00:00Z</updated>
+<summary>要約</summary>
+<content type="html">&lt;p&gt;本文&lt;/p&gt;</content>
+<author><name>Entry Author</name></author>
+<category term="go" label="Go言語"/>
+<category term="atom"/>
+</entry>
+<entry>
+<title>エントリー2</title>
+<id>urn:uuid:entry-2</id>
+<link href="https://example.com/entry/2"/>
+<updated>2024-01-04T00:00:00Z</updated>
+</entry>
+</feed>`
+
+func parseWithDefaultRegistry(t *testing.T, data string) *models.RSSFeed {
+	t.Helper()
+	feed, err := services.NewDefaultParserRegistry().Parse(context.Background(), services.FeedSource{
+		URL:  "https://example.com/feed",
+		Body: strings.NewReader(data),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, feed)
+	return feed
+}
+
+func TestArticle_RSS2の記事情報を変換する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, richRSS2Feed)
+	require.Len(t, feed.Articles, 2)
+
+	a := feed.Articles[0]
+	assert.Equal(t, "tag:example.com,2024:1", a.ID)
+	assert.Equal(t, "要約", a.Summary)
+	assert.Equal(t, "<p>本文<strong>全文</strong></p>", a.ContentHTML, "content:encodedを本文とする")
+	assert.Equal(t, []models.Author{{Name: "山田太郎"}}, a.Authors)
+	assert.Equal(t, []string{"Go", "RSS"}, a.Categories)
+	assert.Equal(t, "https://example.com/1.jpg", a.Image)
+
+	b := feed.Articles[1]
+	assert.Equal(t, "https://example.com/2", b.ID, "guidが無い場合はリンクをIDとする")
+	assert.Equal(t, []models.Author{{Name: "Editor", Email: "editor@example.com"}}, b.Authors)
+	assert.Empty(t, b.ContentHTML)
+	assert.Empty(t, b.Categories)
+}
+
+func TestArticle_RSS1の記事情報を変換する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, richRDFFeed)
+	require.Len(t, feed.Articles, 1)
+
+	a := feed.Articles[0]
+	assert.Equal(t, "https://example.jp/entry/1", a.ID)
+	assert.Equal(t, "<p>本文</p>", a.ContentHTML)
+	assert.Equal(t, []models.Author{{Name: "hatena"}}, a.Authors, "dc:creatorを著者とする")
+	assert.Equal(t, []string{"日記"}, a.Categories, "dc:subjectをカテゴリーとする")
+}
+
+func TestArticle_Atomの記事情報を変換する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, richAtomFeed)
+	require.Len(t, feed.Articles, 2)
+
+	a := feed.Articles[0]
+	assert.Equal(t, "urn:uuid:entry-1", a.ID)
+	assert.Equal(t, "2024-01-02T00:00:00Z", a.PubDate)
+	assert.Equal(t, "2024-01-03T00:00:00Z", a.Updated)
+	assert.Equal(t, "要約", a.Summary)
+	assert.Equal(t, "<p>本文</p>", a.ContentHTML)
+	assert.Equal(t, []models.Author{{Name: "Entry Author"}}, a.Authors)
+	assert.Equal(t, []string{"Go言語", "atom"}, a.Categories, "labelが無い場合はtermを使う")
+
+	b := feed.Articles[1]
+	assert.Equal(t, "2024-01-04T00:00:00Z", b.Updated)
+	assert.Equal(t, []models.Author{{Name: "Feed Author", Email: "feed@example.com"}}, b.Authors, "entryに著者が無い場合はfeedの著者を引き継ぐ")
+}
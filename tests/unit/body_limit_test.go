@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeFeed は約sizeバイトの記事を含むRSS2.0フィードを生成する
+func largeFeed(size int) string {
+	return `<?xml version="1.0"?><rss version="2.0"><channel><title>Large Feed</title><link>https://example.com</link><item><title>A</title><description>` +
+		strings.Repeat("x", size) + `</description></item></channel></rss>`
+}
+
+func TestParseFeeds_上限サイズ以内のフィードはパースできる(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(largeFeed(1000)))
+	}))
+	defer server.Close()
+
+	svc := newTestService(services.WithMaxFeedSize(int64(len(largeFeed(1000)))))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Len(t, feeds, 1)
+	assert.Len(t, errors, 0)
+}
+
+func TestParseFeeds_ContentLengthが上限を超える場合はサイズ超過エラー(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(largeFeed(10000)))
+	}))
+	defer server.Close()
+
+	svc := newTestService(services.WithMaxFeedSize(1000))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Len(t, feeds, 0)
+	require.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeTooLarge, errors[0].Code)
+	assert.Contains(t, errors[0].Message, "サイズ超過")
+}
+
+func TestParseFeeds_ストリーミング中に上限を超えた場合はサイズ超過エラー(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		// Content-Lengthを付けずにチャンク転送する
+		flusher := w.(http.Flusher)
+		body := largeFeed(100000)
+		for i := 0; i < len(body); i += 4096 {
+			end := i + 4096
+			if end > len(body) {
+				end = len(body)
+			}
+			w.Write([]byte(body[i:end]))
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	svc := newTestService(services.WithMaxFeedSize(10000))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Len(t, feeds, 0)
+	require.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeTooLarge, errors[0].Code)
+}
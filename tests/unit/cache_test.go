@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newCacheServer は指定のCache-Controlを返し、リクエスト数を記録するモックサーバーを作成する
+func newCacheServer(t *testing.T, cacheControl string) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Cached Feed</title><link>https://example.com</link><item><title>A</title></item></channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestLRUCache_容量を超えると最も古く参照されたエントリを破棄する(t *testing.T) {
+	cache := services.NewLRUCache(2)
+	cache.Set("a", &services.CacheEntry{})
+	cache.Set("b", &services.CacheEntry{})
+	cache.Get("a") // aを最近参照済みにする
+	cache.Set("c", &services.CacheEntry{})
+
+	_, okA := cache.Get("a")
+	_, okB := cache.Get("b")
+	_, okC := cache.Get("c")
+	assert.True(t, okA)
+	assert.False(t, okB, "最も古く参照されたbが破棄される")
+	assert.True(t, okC)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestParseFeeds_鮮度期間内はキャッシュを返す(t *testing.T) {
+	server, hits := newCacheServer(t, "max-age=300")
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)))
+
+	first, _ := svc.ParseFeeds(context.Background(), []string{server.URL})
+	second, _ := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Equal(t, services.CacheStatusMiss, first[0].Cache)
+	assert.Equal(t, services.CacheStatusHit, second[0].Cache)
+	assert.Equal(t, "Cached Feed", second[0].Title)
+	assert.Len(t, second[0].Articles, 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits), "オリジンへのリクエストは1回のみ")
+}
+
+func TestParseFeeds_noStoreはキャッシュしない(t *testing.T) {
+	server, hits := newCacheServer(t, "no-store")
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)))
+
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	second, _ := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Equal(t, services.CacheStatusMiss, second[0].Cache)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_期限切れキャッシュは条件付きGETで再検証する(t *testing.T) {
+	server, hits := newCacheServer(t, "max-age=0")
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)), services.WithCacheTTL(0, time.Hour))
+
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	second, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Len(t, errors, 0)
+	assert.Equal(t, services.CacheStatusRevalidated, second[0].Cache)
+	assert.Equal(t, "Cached Feed", second[0].Title, "304の場合はキャッシュ本体を返す")
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_最大TTLで鮮度期間を丸める(t *testing.T) {
+	server, hits := newCacheServer(t, "max-age=3600")
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)), services.WithCacheTTL(0, 50*time.Millisecond))
+
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	time.Sleep(60 * time.Millisecond)
+	second, _ := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Equal(t, services.CacheStatusRevalidated, second[0].Cache)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_staleWhileRevalidate期間は古いキャッシュを返し裏で再取得する(t *testing.T) {
+	server, hits := newCacheServer(t, "max-age=0, stale-while-revalidate=60")
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)), services.WithCacheTTL(0, time.Hour))
+
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	second, _ := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Equal(t, services.CacheStatusStale, second[0].Cache)
+	assert.Equal(t, "Cached Feed", second[0].Title)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(hits) == 2 }, time.Second, 10*time.Millisecond, "バックグラウンドで再検証される")
+}
+
+func TestParse_クライアントのETagがキャッシュと一致すればNotModifiedを返す(t *testing.T) {
+	server, hits := newCacheServer(t, "max-age=300")
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)))
+
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	resp := svc.Parse(context.Background(), models.ParseRequest{
+		URLs:       []string{server.URL},
+		Validators: []models.FeedValidator{{URL: server.URL, ETag: `"v1"`}},
+	})
+
+	assert.Empty(t, resp.Feeds)
+	assert.Len(t, resp.NotModified, 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits), "キャッシュで判定しオリジンには問い合わせない")
+}
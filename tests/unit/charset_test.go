@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// parseEncodedFeed はdataを指定したContent-Typeで配信し、パースしたフィードを返す
+func parseEncodedFeed(t *testing.T, data []byte, contentType string) *models.RSSFeed {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}})
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.Feeds, 1)
+	require.Len(t, resp.Feeds[0].Articles, 1)
+	return &resp.Feeds[0]
+}
+
+func TestParse_日本語の文字コードのフィードをUTF8に変換する(t *testing.T) {
+	cases := []struct {
+		name        string
+		fixture     string
+		contentType string
+		wantTitle   string
+		wantSummary string
+	}{
+		{"Shift_JIS（XML宣言）", "shift_jis.xml", "application/rss+xml", "～新着情報～ 髙橋さんの記事", "ＳＪＩＳ・機種依存文字①も含む"},
+		{"EUC-JP（Content-Type）", "euc-jp.xml", "application/rss+xml; charset=EUC-JP", "新着情報 漢字とカタカナ", "EUC-JPの説明文"},
+		{"ISO-2022-JP（XML宣言）", "iso-2022-jp.xml", "text/xml", "新着情報 メールマガジン", "ISO-2022-JPの説明文"},
+		{"Content-TypeがUTF-8でもボディが不正な場合はXML宣言に従う", "shift_jis.xml", "text/xml; charset=UTF-8", "～新着情報～ 髙橋さんの記事", "ＳＪＩＳ・機種依存文字①も含む"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := parseEncodedFeed(t, readFixture(t, tc.fixture), tc.contentType)
+
+			assert.Equal(t, "日本語のフィード", feed.Title)
+			assert.Equal(t, tc.wantTitle, feed.Articles[0].Title)
+			assert.Equal(t, tc.wantSummary, feed.Articles[0].Summary)
+		})
+	}
+}
+
+func TestParse_Content_TypeがUTF8でも先頭1KBより後ろが不正な場合はXML宣言に従う(t *testing.T) {
+	feed := parseEncodedFeed(t, readFixture(t, "shift_jis-ascii-head.xml"), "text/xml; charset=UTF-8")
+
+	assert.Equal(t, "Long ASCII head", feed.Title)
+	assert.Equal(t, "新着記事のお知らせ", feed.Articles[0].Title)
+	assert.Equal(t, "先頭1KBより後ろにある日本語", feed.Articles[0].Summary)
+	assert.Nil(t, feed.Repaired)
+}
+
+func TestParse_Content_TypeのUTF8とXML宣言が食い違ってもUTF8として正しければContent_Typeに従う(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="Shift_JIS"?><rss version="2.0"><channel><title>宣言の誤り</title>` +
+		`<item><title>記事</title><link>https://example.com/1</link></item></channel></rss>`
+
+	feed := parseEncodedFeed(t, []byte(doc), "application/rss+xml; charset=utf-8")
+
+	assert.Equal(t, "宣言の誤り", feed.Title)
+	assert.Equal(t, "記事", feed.Articles[0].Title)
+}
+
+func TestParse_Windows1252のフィードをUTF8に変換する(t *testing.T) {
+	// ISO-8859-1の指定はWindows-1252として扱う（0x80-0x9Fの引用符・ユーロ記号等）
+	feed := parseEncodedFeed(t, readFixture(t, "windows-1252.xml"), "application/atom+xml; charset=ISO-8859-1")
+
+	assert.Equal(t, "Café crème", feed.Title)
+	assert.Equal(t, "“Smart quotes” — naïve €5", feed.Articles[0].Title)
+	assert.Equal(t, "Grüße aus Köln", feed.Articles[0].Summary)
+}
+
+func TestParse_BOMはContent_Typeより優先する(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="Shift_JIS"?><rss version="2.0"><channel><title>BOM付き</title>` +
+		`<item><title>記事</title><link>https://example.com/1</link></item></channel></rss>`
+
+	t.Run("UTF-8", func(t *testing.T) {
+		feed := parseEncodedFeed(t, append([]byte("\xef\xbb\xbf"), doc...), "application/rss+xml; charset=Shift_JIS")
+		assert.Equal(t, "BOM付き", feed.Title)
+		assert.Equal(t, "記事", feed.Articles[0].Title)
+	})
+	t.Run("UTF-16LE", func(t *testing.T) {
+		data, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(doc))
+		require.NoError(t, err)
+		feed := parseEncodedFeed(t, data, "application/rss+xml")
+		assert.Equal(t, "BOM付き", feed.Title)
+		assert.Equal(t, "記事", feed.Articles[0].Title)
+	})
+}
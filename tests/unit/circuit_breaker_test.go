@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeeds_連続失敗したホストはサーキットを開いて即座に失敗させる(t *testing.T) {
+	server, hits := newFlakyServer(t, 100, http.StatusInternalServerError, nil)
+
+	svc := newTestService(services.WithCircuitBreaker(2, time.Minute), services.WithHostConcurrency(1))
+	feeds, errors := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 5))
+
+	assert.Len(t, feeds, 0)
+	require.Len(t, errors, 5)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits), "閾値に達した後はリクエストしない")
+
+	open := 0
+	for _, e := range errors {
+		if e.Code == models.ErrorCodeCircuitOpen {
+			open++
+			assert.Contains(t, e.Message, "サーキットオープン")
+			assert.Greater(t, e.RetryAfter, 0)
+		}
+	}
+	assert.Equal(t, 3, open)
+
+	u, _ := url.Parse(server.URL)
+	diag := svc.Diagnostics()
+	require.Len(t, diag.CircuitBreakers, 1)
+	assert.Equal(t, u.Host, diag.CircuitBreakers[0].Host)
+	assert.Equal(t, services.BreakerOpen, diag.CircuitBreakers[0].State)
+	assert.Equal(t, 2, diag.CircuitBreakers[0].ConsecutiveFailures)
+	assert.NotNil(t, diag.CircuitBreakers[0].OpenedAt)
+}
+
+func TestParseFeeds_ハーフオープンの確認が成功するとサーキットを閉じる(t *testing.T) {
+	server, hits := newFlakyServer(t, 1, http.StatusBadGateway, nil)
+
+	svc := newTestService(services.WithCircuitBreaker(1, 50*time.Millisecond))
+	_, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+	require.Len(t, errors, 1)
+
+	_, errors = svc.ParseFeeds(context.Background(), []string{server.URL})
+	require.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeCircuitOpen, errors[0].Code, "オープン中は即座に失敗する")
+
+	time.Sleep(60 * time.Millisecond)
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+	assert.Len(t, feeds, 1, "ハーフオープンで復旧を確認できれば取得できる")
+	assert.Len(t, errors, 0)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+	assert.Empty(t, svc.Diagnostics().CircuitBreakers, "サーキットが閉じる")
+}
+
+func TestParseFeeds_ハーフオープンの確認が失敗すると再びサーキットを開く(t *testing.T) {
+	server, _ := newFlakyServer(t, 100, http.StatusServiceUnavailable, nil)
+
+	svc := newTestService(services.WithCircuitBreaker(1, 50*time.Millisecond))
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	time.Sleep(60 * time.Millisecond)
+	_, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0].Message, "503", "確認リクエストは実行される")
+
+	_, errors = svc.ParseFeeds(context.Background(), []string{server.URL})
+	require.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeCircuitOpen, errors[0].Code)
+	assert.Equal(t, services.BreakerOpen, svc.Diagnostics().CircuitBreakers[0].State)
+}
+
+func TestParseFeeds_4xxはホストの失敗として数えない(t *testing.T) {
+	server, hits := newFlakyServer(t, 100, http.StatusNotFound, nil)
+
+	svc := newTestService(services.WithCircuitBreaker(1, time.Minute))
+	svc.ParseFeeds(context.Background(), []string{server.URL})
+	_, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0].Message, "404")
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+	assert.Empty(t, svc.Diagnostics().CircuitBreakers)
+}
@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newConcurrencyServer は同時接続数の最大値を記録するモックサーバーを作成する
+func newConcurrencyServer(t *testing.T, delay time.Duration) (*httptest.Server, *int32) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &maxInFlight
+}
+
+func distinctURLs(base string, n int) []string {
+	urls := make([]string, n)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/feed?i=%d", base, i)
+	}
+	return urls
+}
+
+func TestParseFeeds_同時実行数の上限を超えない(t *testing.T) {
+	server, maxInFlight := newConcurrencyServer(t, 20*time.Millisecond)
+
+	svc := newTestService(services.WithMaxConcurrency(3))
+	feeds, errors := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 30))
+
+	assert.Len(t, feeds, 30)
+	assert.Len(t, errors, 0)
+	assert.LessOrEqual(t, atomic.LoadInt32(maxInFlight), int32(3), "同時接続数は3以下")
+}
+
+func TestParseFeeds_大量URLでもデフォルトの同時実行数の上限を超えない(t *testing.T) {
+	server, maxInFlight := newConcurrencyServer(t, 5*time.Millisecond)
+
+	// 同一ホストの同時接続数の上限がデフォルトの同時実行数より小さいため、引き上げてサービス全体の上限を検証する
+	svc := newTestService(services.WithHostConcurrency(services.DefaultMaxConcurrency * 2))
+	feeds, errors := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 500))
+
+	assert.Len(t, feeds, 500)
+	assert.Len(t, errors, 0)
+	assert.LessOrEqual(t, atomic.LoadInt32(maxInFlight), int32(services.DefaultMaxConcurrency), "同時接続数がデフォルト上限以下")
+}
+
+func TestParse_リクエストのMaxConcurrencyで上限を引き下げられる(t *testing.T) {
+	server, maxInFlight := newConcurrencyServer(t, 20*time.Millisecond)
+
+	svc := newTestService(services.WithMaxConcurrency(5))
+	resp := svc.Parse(context.Background(), models.ParseRequest{URLs: distinctURLs(server.URL, 20), MaxConcurrency: 2})
+
+	assert.Len(t, resp.Feeds, 20)
+	assert.LessOrEqual(t, atomic.LoadInt32(maxInFlight), int32(2), "同時接続数は2以下")
+}
+
+func TestParse_リクエストのMaxConcurrencyはサービス上限を超えられない(t *testing.T) {
+	server, maxInFlight := newConcurrencyServer(t, 20*time.Millisecond)
+
+	svc := newTestService(services.WithMaxConcurrency(2))
+	resp := svc.Parse(context.Background(), models.ParseRequest{URLs: distinctURLs(server.URL, 20), MaxConcurrency: 50})
+
+	assert.Len(t, resp.Feeds, 20)
+	assert.LessOrEqual(t, atomic.LoadInt32(maxInFlight), int32(2), "サービス上限の2以下")
+}
@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const conditionalETag = `"v1"`
+const conditionalLastModified = "Mon, 27 Oct 2025 10:00:00 GMT"
+
+// newConditionalServer はETag/Last-Modifiedによる条件付きGETに対応したモックサーバーを作成する
+func newConditionalServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", conditionalETag)
+		w.Header().Set("Last-Modified", conditionalLastModified)
+		if r.Header.Get("If-None-Match") == conditionalETag || r.Header.Get("If-Modified-Since") == conditionalLastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestParse_初回取得時にバリデータを返す(t *testing.T) {
+	server := newConditionalServer(t)
+
+	svc := newTestService()
+	resp := svc.Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}})
+
+	assert.Len(t, resp.Feeds, 1)
+	assert.Equal(t, conditionalETag, resp.Feeds[0].ETag)
+	assert.Equal(t, conditionalLastModified, resp.Feeds[0].LastModified)
+	assert.Empty(t, resp.NotModified)
+}
+
+func TestParse_ETag一致時はNotModifiedを返す(t *testing.T) {
+	server := newConditionalServer(t)
+
+	svc := newTestService()
+	resp := svc.Parse(context.Background(), models.ParseRequest{
+		URLs:       []string{server.URL},
+		Validators: []models.FeedValidator{{URL: server.URL, ETag: conditionalETag}},
+	})
+
+	assert.Empty(t, resp.Feeds)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, []models.FeedValidator{{URL: server.URL, ETag: conditionalETag, LastModified: conditionalLastModified}}, resp.NotModified)
+}
+
+func TestParse_LastModified一致時はNotModifiedを返す(t *testing.T) {
+	server := newConditionalServer(t)
+
+	svc := newTestService()
+	resp := svc.Parse(context.Background(), models.ParseRequest{
+		URLs:       []string{server.URL},
+		Validators: []models.FeedValidator{{URL: server.URL, LastModified: conditionalLastModified}},
+	})
+
+	assert.Empty(t, resp.Feeds)
+	assert.Len(t, resp.NotModified, 1)
+	assert.Equal(t, server.URL, resp.NotModified[0].URL)
+}
+
+func TestParse_バリデータ不一致時はフィード本体を返す(t *testing.T) {
+	server := newConditionalServer(t)
+
+	svc := newTestService()
+	resp := svc.Parse(context.Background(), models.ParseRequest{
+		URLs:       []string{server.URL},
+		Validators: []models.FeedValidator{{URL: server.URL, ETag: `"old"`}},
+	})
+
+	assert.Len(t, resp.Feeds, 1)
+	assert.Equal(t, conditionalETag, resp.Feeds[0].ETag)
+	assert.Empty(t, resp.NotModified)
+}
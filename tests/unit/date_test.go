@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseJSONFeedDate はdate_publishedに指定した文字列を正規化した結果（publishedAt）を返す
+func parseJSONFeedDate(t *testing.T, raw string) string {
+	t.Helper()
+	date, _ := json.Marshal(raw)
+	data := `{"version":"https://jsonfeed.org/version/1.1","title":"T","items":[{"id":"1","date_published":` + string(date) + `}]}`
+	feed, err := (&services.JSONFeedParser{}).Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+	require.NoError(t, err)
+	require.Len(t, feed.Articles, 1)
+	assert.Equal(t, raw, feed.Articles[0].PubDate, "元の文字列は互換性のため維持する")
+	return feed.Articles[0].PublishedAt
+}
+
+func TestArticle_日時をRFC3339のUTCに正規化する(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"RFC 3339", "2024-01-02T09:00:00+09:00", "2024-01-02T00:00:00Z"},
+		{"RFC 3339（小数秒）", "2024-01-02T00:00:00.123Z", "2024-01-02T00:00:00Z"},
+		{"RFC 1123Z", "Tue, 02 Jan 2024 09:00:00 +0900", "2024-01-02T00:00:00Z"},
+		{"タイムゾーン略称", "Tue, 02 Jan 2024 09:00:00 JST", "2024-01-02T00:00:00Z"},
+		{"タイムゾーン略称（米国）", "Mon, 01 Jan 2024 19:00:00 EST", "2024-01-02T00:00:00Z"},
+		{"曜日の誤り・綴り", "Thurs, 2 Jan 2024 09:00:00 +0900", "2024-01-02T00:00:00Z"},
+		{"末尾の括弧書き", "Tue, 02 Jan 2024 09:00:00 +0900 (JST)", "2024-01-02T00:00:00Z"},
+		{"コロン付きオフセット", "2 Jan 2024 09:00:00 +09:00", "2024-01-02T00:00:00Z"},
+		{"秒なし", "Tue, 2 Jan 2024 09:00 +0900", "2024-01-02T00:00:00Z"},
+		{"ISO 8601（基本形式のオフセット）", "2024-01-02T09:00:00+0900", "2024-01-02T00:00:00Z"},
+		{"スペース区切り", "2024-01-02 09:00:00 +0900", "2024-01-02T00:00:00Z"},
+		{"タイムゾーンなしはUTC", "2024-01-02 09:00:00", "2024-01-02T09:00:00Z"},
+		{"日付のみ", "2024-01-02", "2024-01-02T00:00:00Z"},
+		{"スラッシュ区切り", "2024/1/2 9:00", "2024-01-02T09:00:00Z"},
+		{"英語の月名", "January 2, 2024", "2024-01-02T00:00:00Z"},
+		{"日本語", "2024年1月2日 9時00分", "2024-01-02T00:00:00Z"},
+		{"日本語（曜日・秒）", "2024年01月02日(火) 09:00:30", "2024-01-02T00:00:30Z"},
+		{"日本語（午後）", "2024年1月2日 午後9時5分", "2024-01-02T12:05:00Z"},
+		{"日本語（午前0時）", "2024年1月2日 午前12時00分", "2024-01-01T15:00:00Z"},
+		{"日本語（日付のみ）", "2024年1月2日", "2024-01-01T15:00:00Z"},
+		{"全角数字", "２０２４年１月２日　９：００", "2024-01-02T00:00:00Z"},
+		{"存在しない日付", "2024年2月30日", ""},
+		{"解釈できない文字列", "昨日", ""},
+		{"空文字", "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseJSONFeedDate(t, tc.raw))
+		})
+	}
+}
+
+func TestArticle_RSSとAtomの日時を正規化する(t *testing.T) {
+	rss := parseWithDefaultRegistry(t, `<?xml version="1.0"?><rss version="2.0"><channel><title>RSS</title>
+<item><title>A</title><pubDate>Tue, 02 Jan 2024 09:00:00 +0900</pubDate></item>
+<item><title>B</title><pubDate>Tue, 02 Jan 2024 09:00:00 JST</pubDate></item>
+<item><title>C</title><pubDate>2024年1月2日 9時00分</pubDate></item>
+<item><title>D</title><pubDate>不明</pubDate></item>
+</channel></rss>`)
+	require.Len(t, rss.Articles, 4)
+	assert.Equal(t, "Tue, 02 Jan 2024 09:00:00 +0900", rss.Articles[0].PubDate)
+	for _, a := range rss.Articles[:3] {
+		assert.Equal(t, "2024-01-02T00:00:00Z", a.PublishedAt, a.Title)
+	}
+	assert.Empty(t, rss.Articles[3].PublishedAt)
+	assert.Equal(t, "不明", rss.Articles[3].PubDate)
+
+	atom := parseWithDefaultRegistry(t, richAtomFeed)
+	assert.Equal(t, "2024-01-02T00:00:00Z", atom.Articles[0].PublishedAt)
+	assert.Equal(t, "2024-01-03T00:00:00Z", atom.Articles[0].UpdatedAt)
+	assert.Equal(t, "2024-01-04T00:00:00Z", atom.Articles[1].UpdatedAt)
+}
@@ -0,0 +1,274 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDiscoveryServer は指定したパスにHTMLページ・フィードを配信するサーバーを作成する
+// 登録されていないパスは404を返す
+func newDiscoveryServer(t *testing.T, pages map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if len(body) > 0 && body[0] == '<' && body[1] == '!' {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/rss+xml")
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+const discoveryFeed = `<?xml version="1.0"?><rss version="2.0"><channel><title>Blog Feed</title><link>https://example.com</link><item><title>Post</title><link>https://example.com/post</link></item></channel></rss>`
+
+const discoveryHomepage = `<!DOCTYPE html>
+<html><head>
+<title>Blog</title>
+<link rel="stylesheet" href="/style.css">
+<link rel="alternate" hreflang="en" href="/en/">
+<link rel="alternate" type="application/rss+xml" title="RSS" href="/blog/rss.xml">
+<link rel="Alternate" type="application/atom+xml; charset=utf-8" title="Atom" href="https://example.com/atom.xml">
+<link rel="alternate" type="application/feed+json" title="JSON" href="feed.json">
+<link rel="alternate" type="application/rss+xml" href="/blog/rss.xml">
+</head><body><p>Hello</p></body></html>`
+
+func TestParse_HTMLページからフィードを自動検出して取得する(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/":             discoveryHomepage,
+		"/blog/rss.xml": discoveryFeed,
+	})
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/"}})
+
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, server.URL+"/", resp.Results[0].RequestedURL)
+	feed := resp.Results[0].Feed
+	require.NotNil(t, feed)
+	assert.Equal(t, "Blog Feed", feed.Title)
+	assert.Equal(t, server.URL+"/blog/rss.xml", feed.FeedURL)
+	assert.Equal(t, server.URL+"/", feed.DiscoveredFrom)
+}
+
+func TestParse_自動検出したフィードの304はリクエストURLで返す(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(discoveryHomepage))
+	})
+	mux.HandleFunc("/blog/rss.xml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(discoveryFeed))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	pageURL := server.URL + "/"
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{
+		URLs:       []string{pageURL},
+		Validators: []models.FeedValidator{{URL: pageURL, ETag: `"v1"`}},
+	})
+
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.NotModified, 1)
+	assert.Equal(t, pageURL, resp.NotModified[0].URL)
+	require.Len(t, resp.Results, 1)
+	require.NotNil(t, resp.Results[0].Validator)
+	assert.Equal(t, pageURL, resp.Results[0].Validator.URL)
+}
+
+func TestParse_linkタグが無い場合は一般的なパスを試す(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/":     `<!DOCTYPE html><html><head><title>Blog</title></head><body></body></html>`,
+		"/feed": discoveryFeed,
+	})
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}})
+
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.Feeds, 1)
+	assert.Equal(t, "Blog Feed", resp.Feeds[0].Title)
+	assert.Equal(t, server.URL+"/feed", resp.Feeds[0].FeedURL)
+	assert.Equal(t, server.URL, resp.Feeds[0].DiscoveredFrom)
+}
+
+func TestParse_一般的なパスは1件ずつ再試行せずに試し最初に見つかった時点でやめる(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for m := maxInFlight.Load(); n > m && !maxInFlight.CompareAndSwap(m, n); m = maxInFlight.Load() {
+		}
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+
+		switch r.URL.Path {
+		case "/blog/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<!DOCTYPE html><html><head><title>Blog</title></head><body></body></html>`))
+		case "/blog/atom.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(discoveryFeed))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	// 推測したパスの5xxは再試行せず、サーキットブレーカーにも記録しない（記録すると3件目の前に遮断される）
+	svc := newTestService(services.WithRetryPolicy(fastRetryPolicy), services.WithCircuitBreaker(2, time.Minute))
+	resp := svc.Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/blog/"}, MaxConcurrency: 1})
+
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.Feeds, 1)
+	assert.Equal(t, server.URL+"/blog/atom.xml", resp.Feeds[0].FeedURL)
+	assert.Equal(t, 1, resp.Feeds[0].Attempts)
+	assert.Equal(t, []string{"/blog/", "/blog/feed", "/blog/rss.xml", "/blog/atom.xml"}, requested)
+	assert.Equal(t, int32(1), maxInFlight.Load())
+}
+
+func TestParse_フィードを検出できないHTMLページはパースエラー(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/":     `<!DOCTYPE html><html><head><title>Blog</title></head><body></body></html>`,
+		"/feed": `<!DOCTYPE html><html><body>Not a feed</body></html>`,
+	})
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}})
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, server.URL, resp.Errors[0].URL)
+	assert.Equal(t, models.ErrorCodeParseError, resp.Errors[0].Code)
+	assert.Contains(t, resp.Errors[0].Message, "パース失敗")
+}
+
+func TestParse_検出したフィードの取得エラーはリクエストURLで報告する(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/": discoveryHomepage,
+	})
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/"}})
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, server.URL+"/", resp.Errors[0].URL)
+	assert.Equal(t, http.StatusNotFound, resp.Errors[0].HTTPStatus)
+	assert.Contains(t, resp.Errors[0].Message, server.URL+"/blog/rss.xml")
+}
+
+func TestParse_text_htmlで配信されるフィードはそのままパースする(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(discoveryFeed))
+	}))
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, "Blog Feed", feeds[0].Title)
+	assert.Empty(t, feeds[0].DiscoveredFrom)
+}
+
+func TestDiscover_linkタグの候補を宣言順に返す(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{"/blog/": discoveryHomepage})
+
+	resp := newTestService().Discover(context.Background(), server.URL+"/blog/")
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, server.URL+"/blog/", resp.URL)
+	assert.Equal(t, []models.FeedCandidate{
+		{URL: server.URL + "/blog/rss.xml", Title: "RSS", Type: "application/rss+xml", Source: models.FeedSourceLink},
+		{URL: "https://example.com/atom.xml", Title: "Atom", Type: "application/atom+xml", Source: models.FeedSourceLink},
+		{URL: server.URL + "/blog/feed.json", Title: "JSON", Type: "application/feed+json", Source: models.FeedSourceLink},
+	}, resp.Feeds, "フィード以外のalternateと重複は除外し、相対URLはページを基準に解決する")
+}
+
+func TestDiscover_base要素を基準に相対URLを解決する(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/": `<!DOCTYPE html><html><head><base href="/static/"><link rel="alternate" type="application/atom+xml" href="atom.xml"></head></html>`,
+	})
+
+	resp := newTestService().Discover(context.Background(), server.URL+"/")
+
+	require.Len(t, resp.Feeds, 1)
+	assert.Equal(t, server.URL+"/static/atom.xml", resp.Feeds[0].URL)
+}
+
+func TestDiscover_ページと同じディレクトリとルートのパスを試す(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/blog/index.html": `<!DOCTYPE html><html><head><title>Blog</title></head></html>`,
+		"/blog/atom.xml":   discoveryFeed,
+		"/rss.xml":         discoveryFeed,
+	})
+
+	resp := newTestService().Discover(context.Background(), server.URL+"/blog/index.html")
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, []models.FeedCandidate{
+		{URL: server.URL + "/blog/atom.xml", Title: "Blog Feed", Type: "application/rss+xml", Source: models.FeedSourcePath},
+		{URL: server.URL + "/rss.xml", Title: "Blog Feed", Type: "application/rss+xml", Source: models.FeedSourcePath},
+	}, resp.Feeds)
+}
+
+func TestDiscover_フィードURLを指定した場合はそのURLを返す(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{"/feed": discoveryFeed})
+
+	resp := newTestService().Discover(context.Background(), server.URL+"/feed")
+
+	assert.Equal(t, []models.FeedCandidate{
+		{URL: server.URL + "/feed", Title: "Blog Feed", Type: "application/rss+xml", Source: models.FeedSourceSelf},
+	}, resp.Feeds)
+}
+
+func TestDiscover_取得エラーを返す(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{})
+
+	resp := newTestService().Discover(context.Background(), server.URL+"/missing")
+
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, models.ErrorCodeHTTPStatus, resp.Error.Code)
+	assert.NotNil(t, resp.Feeds)
+	assert.Empty(t, resp.Feeds)
+}
+
+func TestParse_本文にHTMLを含むJSON_FeedはHTMLページとみなさない(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/feed+json")
+		w.Write([]byte(`{"version": "https://jsonfeed.org/version/1.1", "title": "JSON Blog", "items": [` +
+			`{"id": "1", "url": "https://example.com/1", "content_html": "<!DOCTYPE html><html><body><p>本文</p></body></html>"}]}`))
+	}))
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, "JSON Blog", feeds[0].Title)
+	require.Len(t, feeds[0].Articles, 1)
+	assert.Empty(t, feeds[0].DiscoveredFrom)
+}
@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeeds_エラーコードを返す(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/404":
+			w.WriteHeader(http.StatusNotFound)
+		case "/503":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body>not a feed</body></html>`))
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedURL := closed.URL
+	closed.Close()
+
+	cases := []struct {
+		name          string
+		url           string
+		wantCode      string
+		wantStatus    int
+		wantRetryable bool
+	}{
+		{"空URL", "", models.ErrorCodeInvalidURL, 0, false},
+		{"スキームなし", "bad-url", models.ErrorCodeInvalidURL, 0, false},
+		{"未対応スキーム", "ftp://example.com/feed", models.ErrorCodeInvalidURL, 0, false},
+		{"404", server.URL + "/404", models.ErrorCodeHTTPStatus, 404, false},
+		{"503", server.URL + "/503", models.ErrorCodeHTTPStatus, 503, true},
+		{"パース失敗", server.URL + "/html", models.ErrorCodeParseError, 0, false},
+		{"リダイレクト上限", server.URL + "/loop", models.ErrorCodeRedirectLimit, 0, false},
+		{"接続拒否", closedURL, models.ErrorCodeConnectionFailed, 0, true},
+		{"名前解決失敗", "http://invalid-domain-that-does-not-exist.example.com/feed", models.ErrorCodeDNSFailure, 0, true},
+	}
+	svc := newTestService()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errors := svc.ParseFeeds(context.Background(), []string{tc.url})
+			require.Len(t, errors, 1)
+			assert.Equal(t, tc.wantCode, errors[0].Code)
+			assert.Equal(t, tc.wantStatus, errors[0].HTTPStatus)
+			assert.Equal(t, tc.wantRetryable, errors[0].Retryable)
+			assert.NotEmpty(t, errors[0].Message, "従来の日本語メッセージも維持される")
+		})
+	}
+}
+
+func TestParseFeeds_パース失敗時は原因をDetailに含める(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`not a feed`))
+	}))
+	defer server.Close()
+
+	_, errors := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeParseError, errors[0].Code)
+	assert.NotEmpty(t, errors[0].Detail)
+	assert.Contains(t, errors[0].Message, errors[0].Detail)
+}
+
+func TestParseFeeds_期限切れはtimeoutを返す(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, errors := newTestService().ParseFeeds(ctx, []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeTimeout, errors[0].Code)
+	assert.True(t, errors[0].Retryable)
+}
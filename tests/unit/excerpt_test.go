@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseExcerpts はitemsをJSON Feedとして配信し、指定した長さで生成した各記事の抜粋を返す
+func parseExcerpts(t *testing.T, length int, items ...map[string]string) []string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"version": "https://jsonfeed.org/version/1.1", "title": "T", "items": items})
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/feed+json")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}, ExcerptLength: length})
+	require.Len(t, resp.Feeds, 1)
+	excerpts := make([]string, 0, len(items))
+	for _, a := range resp.Feeds[0].Articles {
+		excerpts = append(excerpts, a.Excerpt)
+	}
+	return excerpts
+}
+
+func TestArticle_抜粋はタグを除去したプレーンテキスト(t *testing.T) {
+	excerpts := parseExcerpts(t, 0,
+		map[string]string{"id": "1", "summary": "<p>Hello&nbsp;<b>world</b> &amp; &lt;Go&gt;</p>\n\n<p>次の段落</p><script>alert(1)</script>"},
+		map[string]string{"id": "2", "content_text": "  本文の\n  テキスト  "},
+		map[string]string{"id": "3", "content_html": "<div>本文の<br>HTML</div>"},
+		map[string]string{"id": "4"},
+	)
+
+	assert.Equal(t, []string{"Hello world & <Go> 次の段落", "本文の テキスト", "本文の HTML", ""}, excerpts)
+}
+
+func TestArticle_抜粋を書記素クラスタ単位で切り詰める(t *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		length int
+		want   string
+	}{
+		{"短いテキストはそのまま", "吾輩は猫である。", 8, "吾輩は猫である。"},
+		{"日本語", "吾輩は猫である。名前はまだ無い。", 10, "吾輩は猫である。名…"},
+		{"長さ1は省略記号の前に1文字を残す", "吾輩は猫である。", 1, "吾…"},
+		{"結合文字の濁点", "か\u3099か\u3099か\u3099か\u3099", 3, "か\u3099か\u3099…"},
+		{"ZWJで結合した絵文字", "👨\u200d👩\u200d👧👨\u200d👩\u200d👧👨\u200d👩\u200d👧", 2, "👨\u200d👩\u200d👧…"},
+		{"肌色修飾子と異体字セレクタ", "👍🏽❤\ufe0f👍🏽❤\ufe0f", 3, "👍🏽❤\ufe0f…"},
+		{"国旗", "🇯🇵🇺🇸🇬🇧", 2, "🇯🇵…"},
+		{"字母に分解したハングル", "\u1112\u1161\u11ab\u1100\u116e\u11a8\u110b\u1165 \u1110\u1166\u11a8\u1109\u1173\u1110\u1173", 3, "\u1112\u1161\u11ab\u1100\u116e\u11a8…"},
+		{"終声の字母が続くハングルの音節", "가\u11a8가\u11a8가\u11a8", 2, "가\u11a8…"},
+		{"英単語の途中では切らない", "The quick brown fox jumps", 12, "The quick…"},
+		{"単語の区切りで切れる場合はそのまま", "The quick brown fox jumps", 11, "The quick…"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseExcerpts(t, tc.length, map[string]string{"id": "1", "content_text": tc.text})
+			assert.Equal(t, []string{tc.want}, got)
+		})
+	}
+}
+
+func TestArticle_抜粋の長さのデフォルトと上限(t *testing.T) {
+	long := strings.Repeat("あ", services.MaxExcerptLength+100)
+
+	got := parseExcerpts(t, 0, map[string]string{"id": "1", "content_text": long})
+	assert.Equal(t, services.DefaultExcerptLength, utf8.RuneCountInString(got[0]))
+
+	got = parseExcerpts(t, services.MaxExcerptLength*10, map[string]string{"id": "1", "content_text": long})
+	assert.Equal(t, services.MaxExcerptLength, utf8.RuneCountInString(got[0]))
+	assert.True(t, strings.HasSuffix(got[0], "…"))
+}
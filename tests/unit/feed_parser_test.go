@@ -0,0 +1,209 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tsvFeedParser はテスト用の独自形式（1行目がタイトル、以降が「タイトル<TAB>リンク」）のFeedParser
+// Content-Typeがtext/tab-separated-valuesの場合のみ対象とし、受け取ったFeedSourceを記録する
+type tsvFeedParser struct {
+	got services.FeedSource
+}
+
+func (p *tsvFeedParser) Detect(head []byte, header http.Header) bool {
+	return header.Get("Content-Type") == "text/tab-separated-values"
+}
+
+func (p *tsvFeedParser) Parse(ctx context.Context, src services.FeedSource) (*models.RSSFeed, error) {
+	p.got = src
+	scanner := bufio.NewScanner(src.Body)
+	feed := &models.RSSFeed{FeedURL: src.URL, Articles: []models.Article{}}
+	for scanner.Scan() {
+		if feed.Title == "" {
+			feed.Title = scanner.Text()
+			continue
+		}
+		title, link, _ := strings.Cut(scanner.Text(), "\t")
+		feed.Articles = append(feed.Articles, models.Article{Title: title, Link: link})
+	}
+	return feed, scanner.Err()
+}
+
+// anyParser は全ての入力を対象と判定し、固定のフィードを返すFeedParser
+type anyParser struct{ title string }
+
+func (p *anyParser) Detect(head []byte, header http.Header) bool { return true }
+
+func (p *anyParser) Parse(ctx context.Context, src services.FeedSource) (*models.RSSFeed, error) {
+	return &models.RSSFeed{Title: p.title}, nil
+}
+
+// declineParser は対象と判定するが、ボディの先頭を読み取った上でパース時に対象外（nil, nil）を返すFeedParser
+type declineParser struct{}
+
+func (p *declineParser) Detect(head []byte, header http.Header) bool { return true }
+
+func (p *declineParser) Parse(ctx context.Context, src services.FeedSource) (*models.RSSFeed, error) {
+	io.Copy(io.Discard, io.LimitReader(src.Body, 10))
+	return nil, nil
+}
+
+// jsonTitleParser はテスト用の独自形式（{"title": ...}のJSON）のFeedParser
+type jsonTitleParser struct{}
+
+func (p *jsonTitleParser) Detect(head []byte, header http.Header) bool {
+	return strings.HasPrefix(string(head), "{")
+}
+
+func (p *jsonTitleParser) Parse(ctx context.Context, src services.FeedSource) (*models.RSSFeed, error) {
+	var doc struct {
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(src.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &models.RSSFeed{Title: doc.Title}, nil
+}
+
+func TestParserRegistry_内容から形式を判定する(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want services.FeedParser
+	}{
+		{"JSONFeed", "\xef\xbb\xbf  {\"version\":\"https://jsonfeed.org/version/1.1\"}", &services.JSONFeedParser{}},
+		{"Atom", `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>A</title></feed>`, &services.AtomParser{}},
+		{"Atom（名前空間接頭辞付き）", `<?xml version="1.0"?><atom:feed xmlns:atom="http://www.w3.org/2005/Atom"></atom:feed>`, &services.AtomParser{}},
+		{"RSS2.0", `<?xml version="1.0"?><rss version="2.0"><channel></channel></rss>`, &services.RSS2Parser{}},
+		{"RSS2.0（feedburner名前空間）", `<?xml version="1.0"?><rss xmlns:feedburner="http://rssnamespace.org/feedburner/ext/1.0" version='2.0'><channel><feedburner:info uri="x"/></channel></rss>`, &services.RSS2Parser{}},
+		{"RSS1.0", `<?xml version="1.0"?><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"></rdf:RDF>`, &services.RDFParser{}},
+		{"RSS0.91はgofeedにフォールバック", `<?xml version="1.0"?><rss version="0.91"><channel></channel></rss>`, &services.GofeedParser{}},
+	}
+	registry := services.NewDefaultParserRegistry()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.IsType(t, tc.want, registry.Detect([]byte(tc.data), http.Header{}))
+		})
+	}
+}
+
+func TestParserRegistry_優先度の高いパーサーから判定する(t *testing.T) {
+	data := `<?xml version="1.0"?><rss version="2.0"><channel><title>RSS</title></channel></rss>`
+
+	high := services.NewDefaultParserRegistry()
+	high.Register(&anyParser{title: "custom"}, 10)
+	feed, err := high.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", feed.Title, "正の優先度は組み込みパーサーより先に判定される")
+
+	low := services.NewDefaultParserRegistry()
+	low.Register(&anyParser{title: "custom"}, -1000)
+	feed, err = low.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+	require.NoError(t, err)
+	assert.Equal(t, "RSS", feed.Title, "組み込みパーサーが先に判定される")
+
+	same := services.NewParserRegistry()
+	same.Register(&anyParser{title: "first"}, 0)
+	same.Register(&anyParser{title: "second"}, 0)
+	feed, err = same.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+	require.NoError(t, err)
+	assert.Equal(t, "first", feed.Title, "同じ優先度は登録順")
+}
+
+func TestParserRegistry_対応するパーサーが無い場合はエラー(t *testing.T) {
+	registry := services.NewParserRegistry()
+	registry.Register(&services.JSONFeedParser{}, 0)
+
+	_, err := registry.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(`<rss version="2.0"></rss>`)})
+	assert.ErrorIs(t, err, services.ErrUnsupportedFeedFormat)
+
+	registry.Register(&declineParser{}, -1)
+	_, err = registry.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(`<rss version="2.0"></rss>`)})
+	assert.ErrorIs(t, err, services.ErrUnsupportedFeedFormat, "対象と判定した全てのパーサーが対象外と返した場合もエラー")
+}
+
+func TestParserRegistry_パーサーが対象外と返した場合は次のパーサーを試す(t *testing.T) {
+	data := `<?xml version="1.0"?><rss version="2.0"><channel><title>RSS</title></channel></rss>`
+
+	registry := services.NewDefaultParserRegistry()
+	registry.Register(&declineParser{}, 10)
+	feed, err := registry.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+	require.NoError(t, err)
+	assert.Equal(t, "RSS", feed.Title, "読み取られた先頭部分を戻して次のパーサーに渡す")
+
+	// JSONFeedParserは"{"で始まるボディを対象と判定するが、JSON Feedでなければ対象外と返す
+	registry = services.NewDefaultParserRegistry()
+	registry.Register(&jsonTitleParser{}, -1)
+	feed, err = registry.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(`{"title": "独自のJSON", "entries": []}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "独自のJSON", feed.Title)
+}
+
+func TestParserRegistry_gofeedのパーサーは形式が異なれば先頭部分で対象外と返す(t *testing.T) {
+	// コメント内のタグでRSS 2.0とも判定されるが、ルート要素はAtomのフィード（上限を超える長さ）
+	data := `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><!-- 移行前: <rss version="2.0"> --><title>Atom</title>` +
+		`<entry><title>記事</title><id>1</id><summary>` + strings.Repeat("長い本文。", 20000) + `</summary></entry></feed>`
+
+	registry := services.NewParserRegistry()
+	registry.Register(&services.RSS2Parser{}, 10)
+	registry.Register(&services.AtomParser{}, 0)
+	feed, err := registry.Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Atom", feed.Title)
+}
+
+func TestParseFeeds_独自形式のパーサーにURLとヘッダーを渡す(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed.tsv", http.StatusFound)
+	})
+	mux.HandleFunc("/feed.tsv", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/tab-separated-values")
+		w.Header().Set("X-Feed-Format", "tsv")
+		w.Write([]byte("TSV Feed\nFirst\thttps://example.com/1\nSecond\thttps://example.com/2\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	parser := &tsvFeedParser{}
+	feeds, errs := newTestService(services.WithFeedParser(parser, 10)).ParseFeeds(context.Background(), []string{server.URL + "/old"})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, "TSV Feed", feeds[0].Title)
+	assert.Equal(t, server.URL+"/old", feeds[0].FeedURL)
+	require.Len(t, feeds[0].Articles, 2)
+	assert.Equal(t, "Second", feeds[0].Articles[1].Title)
+	assert.Equal(t, server.URL+"/old", parser.got.URL)
+	assert.Equal(t, server.URL+"/feed.tsv", parser.got.FinalURL)
+	assert.Equal(t, "tsv", parser.got.Header.Get("X-Feed-Format"))
+}
+
+func TestParseFeeds_登録したパーサーのみを使う(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>RSS</title></channel></rss>`))
+	}))
+	defer server.Close()
+
+	registry := services.NewParserRegistry()
+	registry.Register(&services.JSONFeedParser{}, 0)
+	_, errs := newTestService(services.WithParserRegistry(registry)).ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, models.ErrorCodeParseError, errs[0].Code)
+	assert.Contains(t, errs[0].Message, services.ErrUnsupportedFeedFormat.Error())
+}
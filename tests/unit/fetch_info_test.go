@@ -0,0 +1,158 @@
+package unit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fetchInfoFeed = `<?xml version="1.0"?><rss version="2.0"><channel><title>Fetch Info</title><link>https://example.com</link><item><title>Item</title><link>https://example.com/1</link></item></channel></rss>`
+
+func TestFetchInfo_ステータスとサイズと所要時間を記録する(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		w.Write([]byte(fetchInfoFeed))
+	}))
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	info := feeds[0].FetchInfo
+	require.NotNil(t, info)
+	assert.Equal(t, http.StatusOK, info.StatusCode)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", info.ContentType)
+	assert.Equal(t, int64(len(fetchInfoFeed)), info.Bytes)
+	assert.Zero(t, info.CompressedBytes, "非圧縮の場合は転送サイズを省略する")
+	assert.Equal(t, server.URL, info.FinalURL)
+	assert.Empty(t, info.Redirects)
+	assert.Greater(t, info.Timings.Total, 0.0)
+	assert.Greater(t, info.Timings.TTFB, 0.0)
+	assert.GreaterOrEqual(t, info.Timings.Total, info.Timings.TTFB)
+}
+
+func TestFetchInfo_gzipの転送サイズと展開後のサイズを記録する(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(strings.Repeat("<!-- padding -->", 100) + fetchInfoFeed))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("Accept-Encoding にgzipが含まれていません: %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, "Fetch Info", feeds[0].Title)
+	info := feeds[0].FetchInfo
+	require.NotNil(t, info)
+	assert.Equal(t, int64(len(strings.Repeat("<!-- padding -->", 100)+fetchInfoFeed)), info.Bytes)
+	assert.Equal(t, int64(compressed.Len()), info.CompressedBytes)
+}
+
+func TestFetchInfo_gzipでも上限は展開後のサイズに適用する(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(largeFeed(64 << 10)))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	_, errs := newTestService(services.WithMaxFeedSize(16<<10)).ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, models.ErrorCodeTooLarge, errs[0].Code)
+}
+
+func TestFetchInfo_リダイレクトの経路と最終URLを記録する(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed", http.StatusFound)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fetchInfoFeed))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL + "/old"})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	info := feeds[0].FetchInfo
+	require.NotNil(t, info)
+	assert.Equal(t, server.URL+"/feed", info.FinalURL)
+	require.Len(t, info.Redirects, 2)
+	assert.Equal(t, server.URL+"/old", info.Redirects[0].URL)
+	assert.Equal(t, http.StatusMovedPermanently, info.Redirects[0].StatusCode)
+	assert.Equal(t, server.URL+"/moved", info.Redirects[1].URL)
+	assert.Equal(t, http.StatusFound, info.Redirects[1].StatusCode)
+}
+
+func TestFetchInfo_エラーにも計測情報を付与する(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errs, 1)
+	info := errs[0].FetchInfo
+	require.NotNil(t, info)
+	assert.Equal(t, http.StatusNotFound, info.StatusCode)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, server.URL, info.FinalURL)
+}
+
+func TestFetchInfo_通信前に失敗した場合は省略する(t *testing.T) {
+	_, errs := newTestService().ParseFeeds(context.Background(), []string{"ftp://example.com/feed"})
+
+	require.Len(t, errs, 1)
+	assert.Nil(t, errs[0].FetchInfo)
+}
+
+func TestFetchInfo_キャッシュから返した場合は省略する(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=300")
+		w.Write([]byte(fetchInfoFeed))
+	}))
+	defer server.Close()
+
+	service := newTestService(services.WithCache(services.NewLRUCache(10)))
+	first, _ := service.ParseFeeds(context.Background(), []string{server.URL})
+	second, _ := service.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.NotNil(t, first[0].FetchInfo)
+	assert.Equal(t, services.CacheStatusHit, second[0].Cache)
+	assert.Nil(t, second[0].FetchInfo)
+}
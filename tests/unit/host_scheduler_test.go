@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeeds_同一ホストへの同時接続数を制限する(t *testing.T) {
+	server, maxInFlight := newConcurrencyServer(t, 20*time.Millisecond)
+
+	svc := newTestService(services.WithMaxConcurrency(10), services.WithHostConcurrency(2))
+	feeds, errors := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 10))
+
+	assert.Len(t, feeds, 10)
+	assert.Len(t, errors, 0)
+	assert.LessOrEqual(t, atomic.LoadInt32(maxInFlight), int32(2), "同一ホストへの同時接続数は2以下")
+}
+
+func TestParseFeeds_同一ホストへのリクエスト間隔を空ける(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	defer server.Close()
+
+	interval := 50 * time.Millisecond
+	svc := newTestService(services.WithHostMinInterval(interval))
+	feeds, _ := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 4))
+
+	assert.Len(t, feeds, 4)
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for i := 1; i < len(starts); i++ {
+		// サーバー側の到着時刻で測るため、タイマー精度と接続確立（最初のリクエストのみダイヤルが入る）の誤差を許容する
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), interval-10*time.Millisecond, "リクエスト間隔が最小間隔以上")
+	}
+}
+
+func TestParseFeeds_同一ホストへのリクエスト間隔はデフォルトで空ける(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	defer server.Close()
+
+	// newTestServiceは間隔を0にするため、デフォルトの設定で作成する
+	svc := services.NewRSSService(services.WithAllowedHosts("127.0.0.1"))
+	feeds, _ := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 3))
+
+	assert.Len(t, feeds, 3)
+	assert.Greater(t, services.DefaultHostMinInterval, time.Duration(0))
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for i := 1; i < len(starts); i++ {
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), services.DefaultHostMinInterval-10*time.Millisecond, "リクエスト間隔がデフォルトの最小間隔以上")
+	}
+}
+
+func TestParseFeeds_429のRetryAfterをErrorInfoに反映し同一ホストを待機させる(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	svc := newTestService(services.WithHostConcurrency(1))
+	feeds, errors := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 3))
+
+	assert.Len(t, feeds, 0)
+	assert.Len(t, errors, 3)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "429応答後は同一ホストへリクエストしない")
+
+	messages := ""
+	for _, e := range errors {
+		assert.Greater(t, e.RetryAfter, 0, "RetryAfterが設定される")
+		assert.LessOrEqual(t, e.RetryAfter, 120)
+		messages += e.Message
+	}
+	assert.Contains(t, messages, "429")
+	assert.Contains(t, messages, "レート制限")
+}
+
+func TestParseFeeds_RetryAfterが待機上限以内なら待ってから取得する(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	defer server.Close()
+
+	svc := newTestService(services.WithHostConcurrency(1), services.WithHostMaxWait(2*time.Second))
+	start := time.Now()
+	feeds, errors := svc.ParseFeeds(context.Background(), distinctURLs(server.URL, 2))
+
+	assert.Len(t, feeds, 1)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, 1, errors[0].RetryAfter)
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond, "Retry-After経過まで待機する")
+}
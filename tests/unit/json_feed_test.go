@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestJSONFeedParser_バージョン1_0(t *testing.T) {
+	feed, err := (&services.JSONFeedParser{}).Parse(context.Background(), services.FeedSource{Body: bytes.NewReader(readFixture(t, "jsonfeed-1.0.json"))})
+
+	require.NoError(t, err)
+	require.NotNil(t, feed)
+	assert.Equal(t, "JSON Feed 1.0 Blog", feed.Title)
+	assert.Equal(t, "https://example.org/", feed.Link)
+	assert.Equal(t, "https://example.org/feed.json", feed.FeedURL)
+	assert.Equal(t, "JSON Feed 1.0 のサンプル", feed.Description)
+	assert.Equal(t, "https://example.org/icon.png", feed.Image, "iconをfaviconより優先する")
+	assert.Equal(t, []models.Author{{Name: "Feed Author", URL: "https://example.org/about", Avatar: "https://example.org/avatar.png"}}, feed.Authors, "1.0のauthorを変換する")
+	require.Len(t, feed.Articles, 2)
+
+	a := feed.Articles[0]
+	assert.Equal(t, "2", a.ID)
+	assert.Equal(t, "HTML本文の記事", a.Title)
+	assert.Equal(t, "https://example.org/posts/2", a.Link)
+	assert.Equal(t, "2024-01-02T09:00:00+09:00", a.PubDate)
+	assert.Equal(t, "2024-01-03T10:30:00+09:00", a.Updated)
+	assert.Equal(t, "短い要約", a.Summary)
+	assert.Equal(t, "<p>こんにちは、<strong>世界</strong></p>", a.ContentHTML)
+	assert.Empty(t, a.ContentText)
+	assert.Equal(t, "https://example.org/images/2.png", a.Image)
+	assert.Equal(t, "https://example.org/images/2-banner.png", a.BannerImage)
+	assert.Equal(t, []models.Author{{Name: "Item Author"}}, a.Authors)
+	assert.Equal(t, []string{"go", "jsonfeed"}, a.Categories)
+	assert.Equal(t, []models.Attachment{{
+		URL:               "https://example.org/audio/2.mp3",
+		MimeType:          "audio/mpeg",
+		Title:             "Episode 2",
+		SizeInBytes:       12345678,
+		DurationInSeconds: 1800.5,
+	}}, a.Attachments)
+
+	b := feed.Articles[1]
+	assert.Equal(t, "1", b.ID, "数値のidも文字列として扱う")
+	assert.Equal(t, "https://other.example.com/article", b.Link, "urlが無い場合はexternal_urlを使う")
+	assert.Equal(t, "テキストのみの記事", b.Summary, "summaryもcontent_htmlも無い場合はcontent_textを要約とする")
+	assert.Equal(t, "テキストのみの記事", b.ContentText)
+	assert.Equal(t, feed.Authors, b.Authors, "記事に著者が無い場合はフィードの著者を引き継ぐ")
+}
+
+func TestJSONFeedParser_バージョン1_1(t *testing.T) {
+	feed, err := (&services.JSONFeedParser{}).Parse(context.Background(), services.FeedSource{Body: bytes.NewReader(readFixture(t, "jsonfeed-1.1.json"))})
+
+	require.NoError(t, err)
+	require.NotNil(t, feed)
+	assert.Equal(t, "JSON Feed 1.1 Blog", feed.Title)
+	assert.Equal(t, "ja", feed.Language)
+	assert.Equal(t, "https://example.net/favicon.ico", feed.Image, "iconが無い場合はfaviconを使う")
+	assert.Equal(t, []models.Author{{Name: "Alice"}, {Name: "Bob", URL: "https://example.net/bob"}}, feed.Authors)
+	require.Len(t, feed.Articles, 2)
+
+	a := feed.Articles[0]
+	assert.Equal(t, "https://example.net/posts/hello", a.ID)
+	assert.Equal(t, "Hello", a.Title)
+	assert.Equal(t, "<p>Hello</p>", a.Summary, "summaryが無い場合はcontent_htmlを要約とする")
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+	assert.Equal(t, "Hello", a.ContentText)
+	assert.Equal(t, []models.Author{{Name: "Carol", Avatar: "https://example.net/carol.png"}}, a.Authors)
+	assert.Equal(t, []string{"news"}, a.Categories)
+	assert.Empty(t, a.Attachments)
+
+	assert.Equal(t, feed.Authors, feed.Articles[1].Authors, "記事に著者が無い場合はフィードの著者を引き継ぐ")
+}
+
+func TestJSONFeedParser_JSON_Feed以外は対象外(t *testing.T) {
+	feed, err := (&services.JSONFeedParser{}).Parse(context.Background(), services.FeedSource{Body: strings.NewReader(`{"version":"1.0","items":[]}`)})
+
+	assert.NoError(t, err)
+	assert.Nil(t, feed)
+}
+
+func TestJSONFeedParser_不正なJSONはエラー(t *testing.T) {
+	feed, err := (&services.JSONFeedParser{}).Parse(context.Background(), services.FeedSource{Body: strings.NewReader(`{"version":`)})
+
+	assert.Error(t, err)
+	assert.Nil(t, feed)
+}
+
+func TestParseFeeds_JSON_Feedを取得する(t *testing.T) {
+	data := readFixture(t, "jsonfeed-1.1.json")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/feed+json")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Empty(t, errs)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, "JSON Feed 1.1 Blog", feeds[0].Title)
+	assert.Equal(t, "https://example.net/feed.json", feeds[0].FeedURL)
+	require.Len(t, feeds[0].Articles, 2)
+	assert.Equal(t, []models.Author{{Name: "Carol", Avatar: "https://example.net/carol.png"}}, feeds[0].Articles[0].Authors)
+}
+
+func TestParseFeeds_JSON_Feed以外のJSONはパースエラー(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"not a feed"}`))
+	}))
+	defer server.Close()
+
+	feeds, errs := newTestService().ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Empty(t, feeds)
+	require.Len(t, errs, 1)
+	assert.Equal(t, models.ErrorCodeParseError, errs[0].Code)
+	assert.Contains(t, errs[0].Message, "パース失敗")
+}
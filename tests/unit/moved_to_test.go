@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRedirectServer は /a → /b → /feed のように指定したステータスでリダイレクトするサーバーを作成する
+// /feed はETagが一致すれば304を返す
+func newRedirectServer(t *testing.T, statuses ...int) *httptest.Server {
+	mux := http.NewServeMux()
+	paths := []string{"/a", "/b", "/c"}
+	for i, status := range statuses {
+		next := "/feed"
+		if i+1 < len(statuses) {
+			next = paths[i+1]
+		}
+		mux.HandleFunc(paths[i], func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, next, status)
+		})
+	}
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", conditionalETag)
+		w.Header().Set("Cache-Control", "max-age=300")
+		if r.Header.Get("If-None-Match") == conditionalETag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(fetchInfoFeed))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMovedTo_恒久的なリダイレクトのみの場合は移転先を返す(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+	}{
+		{name: "301", statuses: []int{http.StatusMovedPermanently}},
+		{name: "308", statuses: []int{http.StatusPermanentRedirect}},
+		{name: "301→308", statuses: []int{http.StatusMovedPermanently, http.StatusPermanentRedirect}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRedirectServer(t, tt.statuses...)
+
+			resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/a"}})
+
+			require.Len(t, resp.Results, 1)
+			assert.Equal(t, server.URL+"/feed", resp.Results[0].MovedTo)
+			require.NotNil(t, resp.Results[0].Feed)
+			assert.Equal(t, server.URL+"/feed", resp.Results[0].Feed.MovedTo)
+		})
+	}
+}
+
+func TestMovedTo_一時的なリダイレクトを含む場合は返さない(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+	}{
+		{name: "302", statuses: []int{http.StatusFound}},
+		{name: "307", statuses: []int{http.StatusTemporaryRedirect}},
+		{name: "301→302", statuses: []int{http.StatusMovedPermanently, http.StatusFound}},
+		{name: "302→301", statuses: []int{http.StatusFound, http.StatusMovedPermanently}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRedirectServer(t, tt.statuses...)
+
+			resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/a"}})
+
+			require.Len(t, resp.Results, 1)
+			assert.Equal(t, models.ResultStatusOK, resp.Results[0].Status)
+			assert.Empty(t, resp.Results[0].MovedTo)
+			assert.Empty(t, resp.Results[0].Feed.MovedTo)
+		})
+	}
+}
+
+func TestMovedTo_リダイレクトが無い場合は返さない(t *testing.T) {
+	server := newRedirectServer(t)
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/feed"}})
+
+	require.Len(t, resp.Results, 1)
+	assert.Empty(t, resp.Results[0].MovedTo)
+}
+
+func TestMovedTo_NotModifiedでも移転先を返す(t *testing.T) {
+	server := newRedirectServer(t, http.StatusMovedPermanently)
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{
+		URLs:       []string{server.URL + "/a"},
+		Validators: []models.FeedValidator{{URL: server.URL + "/a", ETag: conditionalETag}},
+	})
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, models.ResultStatusNotModified, resp.Results[0].Status)
+	assert.Equal(t, server.URL+"/feed", resp.Results[0].MovedTo)
+}
+
+func TestMovedTo_キャッシュから返した場合も移転先を返す(t *testing.T) {
+	server := newRedirectServer(t, http.StatusMovedPermanently)
+
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)))
+	svc.Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/a"}})
+	resp := svc.Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/a"}})
+
+	require.Len(t, resp.Results, 1)
+	require.NotNil(t, resp.Results[0].Feed)
+	assert.Equal(t, services.CacheStatusHit, resp.Results[0].Feed.Cache)
+	assert.Equal(t, server.URL+"/feed", resp.Results[0].MovedTo)
+	assert.Equal(t, server.URL+"/feed", resp.Results[0].Feed.MovedTo)
+}
+
+func TestMovedTo_移転先がエラーの場合は返さない(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/gone", http.StatusMovedPermanently)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/a"}})
+
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, models.ResultStatusError, resp.Results[0].Status)
+	assert.Empty(t, resp.Results[0].MovedTo)
+}
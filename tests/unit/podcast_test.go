@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodcast_フィードのポッドキャスト情報を変換する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, string(readFixture(t, "podcast.xml")))
+
+	explicit := false
+	assert.Equal(t, &models.Podcast{
+		Author:     "Go Podcast Team",
+		Owner:      &models.Author{Name: "Owner", Email: "owner@podcast.example.com"},
+		Categories: []string{"Technology", "Technology > Tech News", "Education"},
+		Explicit:   &explicit,
+		Image:      "https://podcast.example.com/artwork.jpg",
+		Type:       "serial",
+		NewFeedURL: "https://podcast.example.com/new-feed.xml",
+		GUID:       "917393e3-1b1e-5cef-ace4-edaa54e1f810",
+		Funding:    []models.PodcastFunding{{URL: "https://podcast.example.com/support", Title: "番組を支援する"}},
+	}, feed.Podcast)
+}
+
+func TestPodcast_エピソード情報と添付ファイルを変換する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, string(readFixture(t, "podcast.xml")))
+	require.Len(t, feed.Articles, 2)
+
+	a := feed.Articles[0]
+	assert.Equal(t, []models.Attachment{{
+		URL:               "https://cdn.example.com/2.mp3",
+		MimeType:          "audio/mpeg",
+		SizeInBytes:       24986239,
+		DurationInSeconds: 3723,
+	}}, a.Attachments)
+	explicit := true
+	assert.Equal(t, &models.PodcastEpisode{
+		DurationInSeconds: 3723,
+		Episode:           2,
+		Season:            1,
+		EpisodeType:       "full",
+		Explicit:          &explicit,
+		Image:             "https://podcast.example.com/2.jpg",
+		Transcripts: []models.PodcastTranscript{
+			{URL: "https://podcast.example.com/2.vtt", Type: "text/vtt", Language: "ja", Rel: "captions"},
+			{URL: "https://podcast.example.com/2.json", Type: "application/json"},
+		},
+		Chapters: &models.PodcastChapters{URL: "https://podcast.example.com/2-chapters.json", Type: "application/json+chapters"},
+		Funding:  []models.PodcastFunding{{URL: "https://podcast.example.com/support/2", Title: "この回を支援する"}},
+	}, a.Podcast)
+
+	b := feed.Articles[1]
+	assert.Equal(t, []models.Attachment{
+		{URL: "https://cdn.example.com/cover.jpg", MimeType: "image/jpeg", SizeInBytes: 1000},
+		{URL: "https://cdn.example.com/trailer.m4a", MimeType: "audio/x-m4a", DurationInSeconds: 95},
+	}, b.Attachments, "再生時間は音声の添付ファイルに設定し、不正なlengthは省略する")
+	require.NotNil(t, b.Podcast)
+	assert.Equal(t, "trailer", b.Podcast.EpisodeType)
+	assert.Nil(t, b.Podcast.Explicit, "記載が無い場合は省略する")
+}
+
+func TestPodcast_Atomのenclosureリンクを添付ファイルとする(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Atom Podcast</title>
+<entry>
+<title>Episode</title>
+<id>urn:uuid:episode</id>
+<link rel="alternate" href="https://example.com/episode"/>
+<link rel="enclosure" type="audio/mpeg" length="1234" href="https://example.com/episode.mp3"/>
+</entry>
+</feed>`)
+
+	require.Len(t, feed.Articles, 1)
+	assert.Equal(t, []models.Attachment{{URL: "https://example.com/episode.mp3", MimeType: "audio/mpeg", SizeInBytes: 1234}}, feed.Articles[0].Attachments)
+	assert.Nil(t, feed.Articles[0].Podcast)
+	assert.Nil(t, feed.Podcast, "拡張が無いフィードはポッドキャスト情報を省略する")
+}
+
+func TestPodcast_iTunesの列挙値以外の種類は省略する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, `<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+<title>Podcast</title>
+<itunes:type>Serialized</itunes:type>
+<item><title>Episode 1</title><guid>1</guid><itunes:episodeType>Regular</itunes:episodeType></item>
+<item><title>Episode 2</title><guid>2</guid><itunes:episodeType> Bonus </itunes:episodeType></item>
+</channel></rss>`)
+
+	require.NotNil(t, feed.Podcast)
+	assert.Empty(t, feed.Podcast.Type)
+	require.Len(t, feed.Articles, 2)
+	require.NotNil(t, feed.Articles[0].Podcast)
+	assert.Empty(t, feed.Articles[0].Podcast.EpisodeType)
+	require.NotNil(t, feed.Articles[1].Podcast)
+	assert.Equal(t, "bonus", feed.Articles[1].Podcast.EpisodeType)
+}
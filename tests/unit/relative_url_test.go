@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseFromURL はdataを取得元URL（リダイレクト後のURL）がdocURLのフィードとしてパースする
+func parseFromURL(t *testing.T, data, docURL string) *models.RSSFeed {
+	t.Helper()
+	feed, err := services.NewDefaultParserRegistry().Parse(context.Background(), services.FeedSource{
+		URL:      "https://example.com/feed",
+		FinalURL: docURL,
+		Body:     strings.NewReader(data),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, feed)
+	return feed
+}
+
+func TestParse_Atomの入れ子のxml_baseで相対URLを解決する(t *testing.T) {
+	feed := parseFromURL(t, `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:base="/blog/">
+  <title>Base</title>
+  <link href="index.html"/>
+  <link rel="self" href="atom.xml"/>
+  <entry xml:base="2024/">
+    <title>相対</title>
+    <id>tag:example.com,2024:1</id>
+    <link href="post-1.html"/>
+    <link rel="enclosure" type="audio/mpeg" length="100" href="audio/ep1.mp3"/>
+    <content type="html">&lt;p&gt;&lt;img src="images/a.png"&gt;&lt;/p&gt;</content>
+  </entry>
+  <entry xml:base="https://cdn.example.org/wp-atom.php">
+    <title>ディレクトリでないxml:base</title>
+    <id>tag:example.com,2024:2</id>
+    <link href="post-2.html"/>
+  </entry>
+  <entry>
+    <title>xml:baseなし</title>
+    <id>tag:example.com,2024:3</id>
+    <link href="/about"/>
+  </entry>
+</feed>`, "https://example.com/feeds/atom.xml")
+
+	assert.Equal(t, "https://example.com/blog/index.html", feed.Link)
+	assert.Equal(t, "https://example.com/blog/atom.xml", feed.FeedURL)
+	require.Len(t, feed.Articles, 3)
+
+	first := feed.Articles[0]
+	assert.Equal(t, "https://example.com/blog/2024/post-1.html", first.Link)
+	assert.Equal(t, "https://example.com/blog/2024/images/a.png", first.ImageURL)
+	require.Len(t, first.Attachments, 1)
+	assert.Equal(t, "https://example.com/blog/2024/audio/ep1.mp3", first.Attachments[0].URL)
+
+	assert.Equal(t, "https://cdn.example.org/post-2.html", feed.Articles[1].Link)
+	assert.Equal(t, "https://example.com/about", feed.Articles[2].Link)
+}
+
+func TestParse_先頭1KBより後ろのxml_baseで相対URLを解決する(t *testing.T) {
+	feed := parseFromURL(t, `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Late base</title>
+  <subtitle>`+strings.Repeat("長い説明文。", 100)+`</subtitle>
+  <entry xml:base="2024/">
+    <title>相対</title>
+    <id>tag:example.com,2024:1</id>
+    <link href="post-1.html"/>
+    <author xml:base="authors/"><name>Author</name><uri>me.html</uri></author>
+    <link rel="enclosure" type="audio/mpeg" length="100" href="audio/ep1.mp3"/>
+  </entry>
+  <entry>
+    <title>xml:baseなし</title>
+    <id>tag:example.com,2024:2</id>
+    <link href="post-2.html"/>
+  </entry>
+</feed>`, "https://example.com/feeds/atom.xml")
+
+	require.Len(t, feed.Articles, 2)
+	first := feed.Articles[0]
+	assert.Equal(t, "https://example.com/feeds/2024/post-1.html", first.Link)
+	require.Len(t, first.Attachments, 1)
+	assert.Equal(t, "https://example.com/feeds/2024/audio/ep1.mp3", first.Attachments[0].URL, "入れ子の要素を閉じた後はentryのxml:baseに戻る")
+	assert.Equal(t, "https://example.com/feeds/post-2.html", feed.Articles[1].Link)
+}
+
+func TestParse_RSSの相対URLはチャンネルのリンクを基準に解決する(t *testing.T) {
+	feed := parseFromURL(t, `<?xml version="1.0"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel>
+  <title>Relative</title>
+  <link>/blog/</link>
+  <image><url>logo.png</url><title>Relative</title><link>/blog/</link></image>
+  <item>
+    <title>記事</title>
+    <link>posts/1</link>
+    <enclosure url="media/ep1.mp3" type="audio/mpeg" length="100"/>
+    <media:thumbnail url="thumbs/1.jpg" width="320" height="180"/>
+  </item>
+</channel></rss>`, "https://example.com/feeds/rss.xml")
+
+	assert.Equal(t, "https://example.com/blog/", feed.Link)
+	assert.Equal(t, "https://example.com/feed", feed.FeedURL, "自身のURLが無い場合はリクエストURL")
+	assert.Equal(t, "https://example.com/blog/logo.png", feed.Image)
+	require.Len(t, feed.Articles, 1)
+	a := feed.Articles[0]
+	assert.Equal(t, "https://example.com/blog/posts/1", a.Link)
+	assert.Equal(t, "https://example.com/blog/posts/1", a.ID, "guidが無い場合は解決後のリンク")
+	assert.Equal(t, "https://example.com/blog/thumbs/1.jpg", a.ImageURL)
+	require.Len(t, a.Attachments, 1)
+	assert.Equal(t, "https://example.com/blog/media/ep1.mp3", a.Attachments[0].URL)
+}
+
+func TestParse_チャンネルのリンクが無い場合はリダイレクト後のURLを基準に解決する(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/feeds/rss.xml", http.StatusMovedPermanently))
+	mux.HandleFunc("/feeds/rss.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>NoLink</title>
+<item><title>記事</title><link>posts/1</link></item></channel></rss>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL + "/old"}})
+
+	require.Len(t, resp.Feeds, 1)
+	require.Len(t, resp.Feeds[0].Articles, 1)
+	assert.Equal(t, server.URL+"/feeds/posts/1", resp.Feeds[0].Articles[0].Link)
+}
+
+func TestParse_JSON_Feedの相対URLを解決する(t *testing.T) {
+	feed := parseFromURL(t, `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Relative",
+  "home_page_url": "/blog/",
+  "feed_url": "feed.json",
+  "icon": "icon.png",
+  "items": [{"id": "1", "url": "posts/1", "image": "images/1.png",
+    "attachments": [{"url": "media/ep1.mp3", "mime_type": "audio/mpeg"}]}]
+}`, "https://example.com/feeds/feed.json")
+
+	assert.Equal(t, "https://example.com/blog/", feed.Link)
+	assert.Equal(t, "https://example.com/feeds/feed.json", feed.FeedURL)
+	assert.Equal(t, "https://example.com/blog/icon.png", feed.Image)
+	require.Len(t, feed.Articles, 1)
+	a := feed.Articles[0]
+	assert.Equal(t, "https://example.com/blog/posts/1", a.Link)
+	assert.Equal(t, "https://example.com/blog/images/1.png", a.ImageURL)
+	require.Len(t, a.Attachments, 1)
+	assert.Equal(t, "https://example.com/blog/media/ep1.mp3", a.Attachments[0].URL)
+}
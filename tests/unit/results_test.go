@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_結果をリクエストのURL順に返す(t *testing.T) {
+	// 先頭のURLほど応答が遅いサーバー（完了順はリクエスト順の逆になる）
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i, _ := strconv.Atoi(r.URL.Query().Get("i"))
+		time.Sleep(time.Duration(5-i) * 20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/xml")
+		// FeedLinkがリクエストURLと異なるフィード
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>Feed %d</title><link>https://example.com</link><atom:link href="https://feeds.example.com/%d" rel="self"/></channel></rss>`, i, i)
+	}))
+	defer server.Close()
+
+	urls := distinctURLs(server.URL, 5)
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: urls})
+
+	require.Len(t, resp.Feeds, 5)
+	require.Len(t, resp.Results, 5)
+	for i, result := range resp.Results {
+		assert.Equal(t, urls[i], result.RequestedURL)
+		assert.Equal(t, models.ResultStatusOK, result.Status)
+		require.NotNil(t, result.Feed)
+		assert.Equal(t, fmt.Sprintf("Feed %d", i), result.Feed.Title)
+		assert.Equal(t, fmt.Sprintf("https://feeds.example.com/%d", i), result.Feed.FeedURL, "FeedURLが異なってもrequestedUrlで対応付けられる")
+		assert.Equal(t, fmt.Sprintf("Feed %d", i), resp.Feeds[i].Title, "feedsもリクエスト順")
+	}
+}
+
+func TestParse_結果にステータスとエラーを含める(t *testing.T) {
+	server := newConditionalServer(t)
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer notFound.Close()
+
+	urls := []string{server.URL + "/a", notFound.URL, server.URL + "/b", server.URL + "/a"}
+	resp := newTestService().Parse(context.Background(), models.ParseRequest{
+		URLs:       urls,
+		Validators: []models.FeedValidator{{URL: server.URL + "/b", ETag: conditionalETag}},
+	})
+
+	require.Len(t, resp.Results, 4, "重複URLもそれぞれ結果を返す")
+	for i, result := range resp.Results {
+		assert.Equal(t, urls[i], result.RequestedURL)
+	}
+
+	assert.Equal(t, models.ResultStatusOK, resp.Results[0].Status)
+	assert.NotNil(t, resp.Results[0].Feed)
+	assert.Nil(t, resp.Results[0].Error)
+
+	assert.Equal(t, models.ResultStatusError, resp.Results[1].Status)
+	require.NotNil(t, resp.Results[1].Error)
+	assert.Equal(t, models.ErrorCodeHTTPStatus, resp.Results[1].Error.Code)
+	assert.Nil(t, resp.Results[1].Feed)
+
+	assert.Equal(t, models.ResultStatusNotModified, resp.Results[2].Status)
+	require.NotNil(t, resp.Results[2].Validator)
+	assert.Equal(t, conditionalETag, resp.Results[2].Validator.ETag)
+
+	assert.Equal(t, models.ResultStatusOK, resp.Results[3].Status)
+}
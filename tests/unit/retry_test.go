@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetryPolicy はテスト用に待機時間を短くした再試行方針
+var fastRetryPolicy = services.RetryPolicy{
+	MaxAttempts:       3,
+	BaseDelay:         time.Millisecond,
+	MaxDelay:          2 * time.Second,
+	RetryableStatuses: []int{502, 503, 504},
+}
+
+// newFlakyServer は最初のfailures回だけstatusを返し、以降は正常なフィードを返すモックサーバーを作成する
+func newFlakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Flaky Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestParseFeeds_一時的な503は再試行して成功する(t *testing.T) {
+	server, hits := newFlakyServer(t, 2, http.StatusServiceUnavailable, nil)
+
+	svc := newTestService(services.WithRetryPolicy(fastRetryPolicy))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, feeds, 1)
+	assert.Len(t, errors, 0)
+	assert.Equal(t, "Flaky Feed", feeds[0].Title)
+	assert.Equal(t, 3, feeds[0].Attempts, "試行回数が記録される")
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_最大試行回数を超えたらエラーに試行回数を記録する(t *testing.T) {
+	server, hits := newFlakyServer(t, 10, http.StatusBadGateway, nil)
+
+	svc := newTestService(services.WithRetryPolicy(fastRetryPolicy))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Len(t, feeds, 0)
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0].Message, "502")
+	assert.Equal(t, 3, errors[0].Attempts)
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_再試行対象外のステータスは再試行しない(t *testing.T) {
+	server, hits := newFlakyServer(t, 10, http.StatusNotFound, nil)
+
+	svc := newTestService(services.WithRetryPolicy(fastRetryPolicy))
+	_, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Equal(t, 1, errors[0].Attempts)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_RetryAfterに従って待機してから再試行する(t *testing.T) {
+	server, hits := newFlakyServer(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"1"}})
+
+	svc := newTestService(services.WithRetryPolicy(fastRetryPolicy))
+	start := time.Now()
+	feeds, _ := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, feeds, 1)
+	assert.Equal(t, 2, feeds[0].Attempts)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond, "Retry-Afterの秒数だけ待機する")
+}
+
+func TestParseFeeds_RetryAfterが待機上限を超える場合は再試行しない(t *testing.T) {
+	server, hits := newFlakyServer(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"60"}})
+
+	svc := newTestService(services.WithRetryPolicy(fastRetryPolicy))
+	_, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Equal(t, 60, errors[0].RetryAfter)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
+
+func TestParseFeeds_コンテキストの期限内に収まらない再試行は行わない(t *testing.T) {
+	server, hits := newFlakyServer(t, 10, http.StatusServiceUnavailable, nil)
+
+	policy := fastRetryPolicy
+	policy.BaseDelay = time.Second
+	svc := newTestService(services.WithRetryPolicy(policy))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, errors := svc.ParseFeeds(ctx, []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+	assert.Less(t, time.Since(start), 200*time.Millisecond, "期限を待たずに結果を返す")
+}
+
+func TestParseFeeds_再試行方針を指定しない場合は再試行しない(t *testing.T) {
+	server, hits := newFlakyServer(t, 1, http.StatusServiceUnavailable, nil)
+
+	svc := newTestService()
+	_, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	require.Len(t, errors, 1)
+	assert.Equal(t, 1, errors[0].Attempts)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
@@ -2,9 +2,11 @@ package unit
 
 import (
 	"context"
+	"feed-parallel-parse-api/pkg/models"
 	"feed-parallel-parse-api/pkg/services"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +14,7 @@ import (
 )
 
 func TestParseFeeds_エラーケース(t *testing.T) {
-	svc := services.NewRSSService()
+	svc := newTestService()
 	// 空URL
 	feeds, errors := svc.ParseFeeds(context.Background(), []string{""})
 	assert.Empty(t, feeds)
@@ -34,7 +36,7 @@ func TestParseFeeds_エラーケース(t *testing.T) {
 
 func TestParseFeeds_仕様テスト(t *testing.T) {
 	t.Run("URLリストが空ならnilを返す", func(t *testing.T) {
-		svc := services.NewRSSService()
+		svc := newTestService()
 		feeds, errors := svc.ParseFeeds(context.Background(), []string{})
 		assert.Nil(t, feeds)
 		assert.Nil(t, errors)
@@ -64,7 +66,7 @@ func TestParseFeeds_RealHTTP_Success(t *testing.T) {
 	defer server.Close()
 
 	// テスト実行
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	// 検証
@@ -96,7 +98,7 @@ func TestParseFeeds_RSS20(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	assert.Len(t, feeds, 1)
@@ -123,7 +125,7 @@ func TestParseFeeds_Atom(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	assert.Len(t, feeds, 1)
@@ -139,13 +141,15 @@ func TestParseFeeds_HTTP404(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	assert.Len(t, feeds, 0)
 	assert.Len(t, errors, 1)
 	assert.Contains(t, errors[0].Message, "404")
 	assert.Contains(t, errors[0].Message, "HTTPエラー")
+	assert.Equal(t, models.ErrorCodeHTTPStatus, errors[0].Code)
+	assert.Equal(t, http.StatusNotFound, errors[0].HTTPStatus)
 }
 
 // T022: 500エラーテスト
@@ -155,18 +159,21 @@ func TestParseFeeds_HTTP500(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	assert.Len(t, feeds, 0)
 	assert.Len(t, errors, 1)
 	assert.Contains(t, errors[0].Message, "500")
 	assert.Contains(t, errors[0].Message, "HTTPエラー")
+	assert.Equal(t, models.ErrorCodeHTTPStatus, errors[0].Code)
+	assert.Equal(t, http.StatusInternalServerError, errors[0].HTTPStatus)
+	assert.True(t, errors[0].Retryable)
 }
 
 // T023: 無効なURLテスト
 func TestParseFeeds_InvalidURL(t *testing.T) {
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{"http://invalid-domain-that-does-not-exist.example.com/feed"})
 
 	assert.Len(t, feeds, 0)
@@ -183,12 +190,14 @@ func TestParseFeeds_ParseError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	assert.Len(t, feeds, 0)
 	assert.Len(t, errors, 1)
 	assert.Contains(t, errors[0].Message, "パース失敗")
+	assert.Equal(t, models.ErrorCodeParseError, errors[0].Code)
+	assert.False(t, errors[0].Retryable)
 }
 
 func TestFeedParser_Parse(t *testing.T) {
@@ -220,10 +229,18 @@ func TestFeedParser_Parse(t *testing.T) {
 			wantTitle: "Atom Title",
 			wantLen:   1,
 		},
+		{
+			name:      "JSONFeed",
+			parser:    &services.JSONFeedParser{},
+			data:      `{"version":"https://jsonfeed.org/version/1.1","title":"JSON Feed Title","home_page_url":"http://example.com/","items":[{"id":"4","url":"http://example.com/4","content_text":"desc4"}]}`,
+			wantTitle: "JSON Feed Title",
+			wantLen:   1,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			feed, err := tc.parser.Parse(context.Background(), []byte(tc.data))
+			assert.True(t, tc.parser.Detect([]byte(tc.data), nil), "形式を判定できる")
+			feed, err := tc.parser.Parse(context.Background(), services.FeedSource{URL: "http://example.com/feed", Body: strings.NewReader(tc.data)})
 			assert.NoError(t, err)
 			assert.NotNil(t, feed)
 			assert.Equal(t, tc.wantTitle, feed.Title)
@@ -234,7 +251,7 @@ func TestFeedParser_Parse(t *testing.T) {
 
 // T027: タイムアウト設定のユニットテスト
 func TestRSSService_HTTPClientTimeout(t *testing.T) {
-	svc := services.NewRSSService()
+	svc := newTestService()
 	// HTTPクライアントがタイムアウト設定を持つことを確認
 	// (リフレクションを使わずに動作確認するため、実際にタイムアウトするサーバーでテスト)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -254,7 +271,7 @@ func TestRSSService_HTTPClientTimeout(t *testing.T) {
 
 // T028: タイムアウト発生時のエラーハンドリングテスト
 func TestRSSService_TimeoutError(t *testing.T) {
-	svc := services.NewRSSService()
+	svc := newTestService()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(15 * time.Second)
 	}))
@@ -283,7 +300,7 @@ func TestRSSService_RedirectLimit(t *testing.T) {
 	}))
 	defer server.Close()
 
-	svc := services.NewRSSService()
+	svc := newTestService()
 	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
 
 	assert.Empty(t, feeds, "リダイレクト上限超過によりフィードは取得できない")
@@ -313,7 +330,7 @@ func TestRSSService_FeedLinkからFeedURL設定(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	// Assert: FeedURLが正しく設定されている
@@ -345,7 +362,7 @@ func TestRSSService_FeedLinkが空の場合のフォールバック(t *testing.T
 	}))
 	defer server.Close()
 
-	service := services.NewRSSService()
+	service := newTestService()
 	feeds, errors := service.ParseFeeds(context.Background(), []string{server.URL})
 
 	// Assert: feed.FeedLinkが空なので、リクエストされたURLにフォールバック
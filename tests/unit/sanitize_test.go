@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unsafeSummary = `<p onclick="steal()">Hello <b>world</b> &amp; <a href="/about" target="_blank" style="color:red">about</a></p>` +
+	`<script>alert(1)</script><iframe src="https://tracker.example.com/"></iframe>` +
+	`<h2>見出し</h2><img src="images/a.png" alt="A" onerror="steal()"><img src="https://tracker.example.com/p.gif" width="1" height="1">` +
+	`<a href="javascript:alert(1)">js</a><!-- comment --><custom-tag>中身</custom-tag>`
+
+// newSanitizeServer はunsafeSummaryを要約に含むRSSフィードを配信するサーバーを作成する
+func newSanitizeServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel>
+<title>Unsafe</title><link>https://blog.example.com/</link>
+<item><title>記事</title><link>https://blog.example.com/posts/1</link>
+<description><![CDATA[` + unsafeSummary + `]]></description>
+<content:encoded><![CDATA[<div><p>本文</p><style>p{}</style></div>]]></content:encoded>
+</item></channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestParse_要約と本文をサニタイズする(t *testing.T) {
+	server := newSanitizeServer(t)
+
+	cases := []struct {
+		policy      string
+		wantSummary string
+		wantContent string
+	}{
+		{
+			"", // 省略時はsafe-basic
+			`<p>Hello <b>world</b> &amp; <a href="https://blog.example.com/about" rel="nofollow noopener noreferrer">about</a></p>見出し<a>js</a>中身`,
+			`<p>本文</p>`,
+		},
+		{
+			models.SanitizeStripAll,
+			`Hello world &amp; about 見出し js 中身`,
+			`本文`,
+		},
+		{
+			models.SanitizeSafeRich,
+			`<p>Hello <b>world</b> &amp; <a href="https://blog.example.com/about" rel="nofollow noopener noreferrer">about</a></p>` +
+				`<h2>見出し</h2><img src="https://blog.example.com/posts/images/a.png" alt="A"><a>js</a>中身`,
+			`<div><p>本文</p></div>`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.policy, func(t *testing.T) {
+			resp := newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}, Sanitize: tc.policy})
+
+			require.Len(t, resp.Feeds, 1)
+			require.Len(t, resp.Feeds[0].Articles, 1)
+			a := resp.Feeds[0].Articles[0]
+			assert.Equal(t, tc.wantSummary, a.SanitizedSummary)
+			assert.Equal(t, tc.wantContent, a.SanitizedContentHTML)
+			assert.Equal(t, unsafeSummary, a.Summary, "元のHTMLは互換性のためそのまま返す")
+		})
+	}
+}
+
+func TestParse_キャッシュしたフィードはリクエストごとの方針でサニタイズする(t *testing.T) {
+	server := newSanitizeServer(t)
+	svc := newTestService(services.WithCache(services.NewLRUCache(10)))
+
+	first := svc.Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}, Sanitize: models.SanitizeStripAll})
+	second := svc.Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}, Sanitize: models.SanitizeSafeRich})
+
+	require.Len(t, first.Feeds, 1)
+	require.Len(t, second.Feeds, 1)
+	assert.Equal(t, services.CacheStatusHit, second.Feeds[0].Cache)
+	assert.NotContains(t, first.Feeds[0].Articles[0].SanitizedSummary, "<")
+	assert.Contains(t, second.Feeds[0].Articles[0].SanitizedSummary, "<h2>")
+	assert.NotSame(t, &first.Results[0].Feed.Articles[0], &second.Results[0].Feed.Articles[0], "キャッシュの記事を共有しない")
+}
+
+func TestValidateSanitizePolicy_未知の方針はエラー(t *testing.T) {
+	for _, policy := range []string{"", models.SanitizeStripAll, models.SanitizeSafeBasic, models.SanitizeSafeRich} {
+		assert.NoError(t, services.ValidateSanitizePolicy(policy), policy)
+	}
+	assert.ErrorIs(t, services.ValidateSanitizePolicy("none"), services.ErrInvalidSanitizePolicy)
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newCountingServer は応答を遅延させ、リクエスト数を記録するモックサーバーを作成する
+func newCountingServer(t *testing.T, delay time.Duration) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Shared Feed</title><link>https://example.com</link></channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestParseFeeds_同一リクエスト内の重複URLは1回だけ取得する(t *testing.T) {
+	server, hits := newCountingServer(t, 50*time.Millisecond)
+
+	svc := newTestService()
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL, server.URL, server.URL, server.URL})
+
+	assert.Len(t, feeds, 4, "リクエストされたURLごとに結果を返す")
+	assert.Len(t, errors, 0)
+	for _, feed := range feeds {
+		assert.Equal(t, "Shared Feed", feed.Title)
+		assert.Equal(t, server.URL, feed.FeedURL)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits), "オリジンへのリクエストは1回のみ")
+}
+
+func TestParseFeeds_並行するAPI呼び出しの同一URLは1回だけ取得する(t *testing.T) {
+	server, hits := newCountingServer(t, 100*time.Millisecond)
+	svc := newTestService()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+			assert.Len(t, feeds, 1)
+			assert.Len(t, errors, 0)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits), "オリジンへのリクエストは1回のみ")
+}
+
+func TestParseFeeds_キャンセルした呼び出し元があっても他の待機者は結果を受け取れる(t *testing.T) {
+	server, _ := newCountingServer(t, 100*time.Millisecond)
+	svc := newTestService()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, errors := svc.ParseFeeds(ctx, []string{server.URL})
+		assert.Len(t, errors, 1, "キャンセルした呼び出し元はエラーになる")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+	<-done
+	assert.Len(t, feeds, 1)
+	assert.Len(t, errors, 0)
+}
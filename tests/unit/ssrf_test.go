@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeeds_内部アドレスへの接続を拒否する(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name string
+		url  string
+	}{
+		{"ループバックIP", server.URL},
+		{"ループバックに解決されるホスト名", strings.Replace(server.URL, "127.0.0.1", "localhost", 1)},
+		{"メタデータエンドポイント", "http://169.254.169.254/latest/meta-data/"},
+		{"プライベートアドレス", "http://10.0.0.1/feed"},
+		{"IPv6ループバック", "http://[::1]:1/feed"},
+	}
+	svc := services.NewRSSService()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			feeds, errors := svc.ParseFeeds(context.Background(), []string{tc.url})
+			assert.Len(t, feeds, 0)
+			assert.Len(t, errors, 1)
+			assert.Equal(t, models.ErrorCodeBlocked, errors[0].Code)
+			assert.Contains(t, errors[0].Message, "アクセス拒否")
+		})
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&hits), "内部アドレスにはリクエストが届かない")
+}
+
+func TestParseFeeds_リダイレクト先の内部アドレスも拒否する(t *testing.T) {
+	var feedHits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/feed" {
+			atomic.AddInt32(&feedHits, 1)
+			return
+		}
+		// 許可されたホスト名から許可されていないIPアドレスへリダイレクト
+		http.Redirect(w, r, strings.Replace("http://"+r.Host, "localhost", "127.0.0.1", 1)+"/feed", http.StatusFound)
+	}))
+	defer server.Close()
+
+	svc := services.NewRSSService(services.WithAllowedHosts("localhost"))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/start"})
+
+	assert.Len(t, feeds, 0)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, models.ErrorCodeBlocked, errors[0].Code)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&feedHits))
+}
+
+func TestParseFeeds_許可リストのCIDRには接続できる(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Local Feed</title><link>http://localhost</link></channel></rss>`))
+	}))
+	defer server.Close()
+
+	svc := services.NewRSSService(services.WithAllowedHosts("127.0.0.0/8"))
+	feeds, errors := svc.ParseFeeds(context.Background(), []string{server.URL})
+
+	assert.Len(t, feeds, 1)
+	assert.Len(t, errors, 0)
+	assert.Equal(t, "Local Feed", feeds[0].Title)
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const thumbnailFeed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel>
+<title>Thumbnails</title>
+<link>https://example.com/blog/</link>
+<item>
+<title>media:thumbnail</title>
+<link>https://example.com/blog/1</link>
+<media:group>
+<media:thumbnail url="https://example.com/1-small.jpg" width="120" height="90"/>
+<media:thumbnail url="https://example.com/1-large.jpg" width="640" height="480"/>
+</media:group>
+<media:content url="https://example.com/1-content.jpg" medium="image" width="1280" height="960"/>
+</item>
+<item>
+<title>media:content</title>
+<link>https://example.com/blog/2</link>
+<media:content url="https://example.com/2.mp4" type="video/mp4"/>
+<media:content url="https://example.com/2.png" type="image/png" width="800" height="600"/>
+</item>
+<item>
+<title>enclosure</title>
+<link>https://example.com/blog/3</link>
+<enclosure url="https://example.com/3.mp3" type="audio/mpeg" length="1"/>
+<enclosure url="https://example.com/3.jpg" type="image/jpeg" length="1"/>
+</item>
+<item>
+<title>itunes:image</title>
+<link>https://example.com/blog/4</link>
+<description>&lt;img src="/4-body.jpg"&gt;</description>
+<itunes:image href="https://example.com/4-cover.jpg"/>
+</item>
+<item>
+<title>本文の画像</title>
+<link>/blog/posts/5</link>
+<description>&lt;img src="https://tracker.example.com/p.gif" width="1" height="1"&gt;&lt;img src="data:image/gif;base64,AAAA"&gt;&lt;p&gt;本文&lt;/p&gt;&lt;img src="images/5.jpg" width="300" height="200"&gt;</description>
+</item>
+<item>
+<title>画像なし</title>
+<link>https://example.com/blog/6</link>
+<description>本文のみ</description>
+</item>
+</channel>
+</rss>`
+
+func TestArticle_サムネイルを選ぶ(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, thumbnailFeed)
+	require.Len(t, feed.Articles, 6)
+
+	cases := []struct {
+		url           string
+		width, height int
+	}{
+		{"https://example.com/1-large.jpg", 640, 480},
+		{"https://example.com/2.png", 800, 600},
+		{"https://example.com/3.jpg", 0, 0},
+		{"https://example.com/4-cover.jpg", 0, 0},
+		{"https://example.com/blog/posts/images/5.jpg", 300, 200},
+		{"", 0, 0},
+	}
+	for i, tc := range cases {
+		a := feed.Articles[i]
+		assert.Equal(t, tc.url, a.ImageURL, a.Title)
+		assert.Equal(t, tc.width, a.ImageWidth, a.Title)
+		assert.Equal(t, tc.height, a.ImageHeight, a.Title)
+	}
+}
+
+func TestArticle_Atomの本文の画像を記事のリンクを基準に解決する(t *testing.T) {
+	feed := parseWithDefaultRegistry(t, `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Atom</title>
+<link href="https://example.org/"/>
+<entry>
+<title>Entry</title>
+<id>urn:uuid:1</id>
+<link href="/2024/01/entry"/>
+<content type="html">&lt;p&gt;&lt;img src="photo.jpg"&gt;&lt;/p&gt;</content>
+</entry>
+</feed>`)
+
+	require.Len(t, feed.Articles, 1)
+	assert.Equal(t, "https://example.org/2024/01/photo.jpg", feed.Articles[0].ImageURL)
+}
+
+func TestArticle_JSON_Feedのサムネイル(t *testing.T) {
+	data := `{"version":"https://jsonfeed.org/version/1.1","title":"T","home_page_url":"https://example.net/",
+"items":[
+{"id":"1","url":"https://example.net/1","image":"/images/1.png","content_html":"<img src=\"/body.png\">"},
+{"id":"2","url":"https://example.net/2","banner_image":"https://example.net/banner.png"},
+{"id":"3","url":"https://example.net/posts/3","content_html":"<p>本文</p><img src=\"3.png\" width=\"64\" height=\"48\">"}
+]}`
+	feed, err := (&services.JSONFeedParser{}).Parse(context.Background(), services.FeedSource{Body: strings.NewReader(data)})
+	require.NoError(t, err)
+	require.Len(t, feed.Articles, 3)
+
+	assert.Equal(t, "https://example.net/images/1.png", feed.Articles[0].ImageURL)
+	assert.Equal(t, "https://example.net/banner.png", feed.Articles[1].ImageURL)
+	assert.Equal(t, "https://example.net/posts/3.png", feed.Articles[2].ImageURL)
+	assert.Equal(t, 64, feed.Articles[2].ImageWidth)
+	assert.Equal(t, 48, feed.Articles[2].ImageHeight)
+}
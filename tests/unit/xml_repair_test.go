@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"feed-parallel-parse-api/pkg/models"
+	"feed-parallel-parse-api/pkg/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseMalformedFeed はbodyをRSSとして配信し、パース結果を返す
+func parseMalformedFeed(t *testing.T, body string) models.ParseResponse {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=UTF-8")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return newTestService().Parse(context.Background(), models.ParseRequest{URLs: []string{server.URL}})
+}
+
+func TestParse_不正なXMLを修復してパースする(t *testing.T) {
+	cases := []struct {
+		name         string
+		body         string
+		wantTitle    string
+		wantSummary  string
+		wantRepaired []string
+	}{
+		{
+			"制御文字と実体参照になっていないアンパサンド",
+			`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title>` +
+				"<item><title>AT&T\x0b news</title><link>https://example.com/?a=1&b=2</link>" +
+				`<description><![CDATA[a && b]]></description></item></channel></rss>`,
+			"AT&T news", "a && b",
+			[]string{models.RepairInvalidCharacters, models.RepairUnescapedAmpersand},
+		},
+		{
+			"不正な文字参照",
+			`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title>` +
+				`<item><title>before&#x0B;after&#1;</title><description>ok &amp; fine</description></item></channel></rss>`,
+			"beforeafter", "ok & fine",
+			[]string{models.RepairInvalidCharacters},
+		},
+		{
+			"ルート要素の後ろの不要な内容",
+			`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title>` +
+				"<item><title>記事\x01</title><description>本文</description></item></channel></rss>\n" +
+				"<br />\n<b>Warning</b>:  Cannot modify header information \x00",
+			"記事", "本文",
+			[]string{models.RepairInvalidCharacters, models.RepairTrailingGarbage},
+		},
+		{
+			// gofeedがそのまま読める誤りも修復して報告する
+			"パースできる実体参照になっていないアンパサンドと後ろの不要な内容",
+			`<?xml version="1.0"?><rss version="2.0"><channel><title>Q&A</title>` +
+				`<item><title>Tom & Jerry</title><link>https://example.com/?a=1&b=2</link><description>本文</description></item>` +
+				"</channel></rss><!-- cache -->\n<b>Warning</b>: Cannot modify header information",
+			"Tom & Jerry", "本文",
+			[]string{models.RepairTrailingGarbage, models.RepairUnescapedAmpersand},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := parseMalformedFeed(t, tc.body)
+
+			require.Empty(t, resp.Errors)
+			require.Len(t, resp.Feeds, 1)
+			feed := resp.Feeds[0]
+			assert.Equal(t, tc.wantRepaired, feed.Repaired)
+			require.Len(t, feed.Articles, 1)
+			assert.Equal(t, tc.wantTitle, feed.Articles[0].Title)
+			assert.Equal(t, tc.wantSummary, feed.Articles[0].Summary)
+		})
+	}
+}
+
+func TestParse_読み取りの途中で切れた文字参照も除去する(t *testing.T) {
+	body := `<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title>` +
+		`<item><title>before&#x0B;after&#12354;</title></item></channel></rss>`
+
+	feed, err := services.NewDefaultParserRegistry().Parse(context.Background(), services.FeedSource{
+		URL:  "https://example.com/feed",
+		Body: iotest.OneByteReader(strings.NewReader(body)),
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, feed)
+	require.Len(t, feed.Articles, 1)
+	assert.Equal(t, "beforeafterあ", feed.Articles[0].Title)
+	assert.Equal(t, []string{models.RepairInvalidCharacters}, feed.Repaired)
+}
+
+func TestParse_正しいXMLは修復しない(t *testing.T) {
+	// 実体参照・CDATAセクション内の"&"と、ルート要素の後ろのコメントはXMLとして正しい
+	resp := parseMalformedFeed(t, `<?xml version="1.0"?><rss version="2.0"><channel><title>Q&amp;A &#x26; more</title>`+
+		`<item><title>記事</title><description><![CDATA[a && b]]></description></item></channel></rss><!-- cache -->`+"\n")
+
+	require.Len(t, resp.Feeds, 1)
+	assert.Equal(t, "Q&A & more", resp.Feeds[0].Title)
+	require.Len(t, resp.Feeds[0].Articles, 1)
+	assert.Equal(t, "a && b", resp.Feeds[0].Articles[0].Summary)
+	assert.Nil(t, resp.Feeds[0].Repaired)
+}
+
+func TestParse_修復してもパースできない場合はパース失敗(t *testing.T) {
+	resp := parseMalformedFeed(t, "<?xml version=\"1.0\"?><rss version=\"2.0\"><channel><title>\x0b")
+
+	assert.Empty(t, resp.Feeds)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, models.ErrorCodeParseError, resp.Errors[0].Code)
+}